@@ -0,0 +1,22 @@
+package dto
+
+import "encoding/json"
+
+// SetLogLevelReq changes the log level, of one component when Component is set, for Duration.
+// The layers log as the http, service and repository components, see logger.ComponentHTTP.
+type SetLogLevelReq struct {
+	Level     string `json:"level" binding:"required,oneof=debug info warn error" message:"validation.admin.level"`
+	Component string `json:"component" binding:"omitempty,max=64" message:"validation.admin.component"`
+	Duration  string `json:"duration"`
+}
+
+// ListLogsReq selects the recent log entries at Level or above, at most Limit of them
+type ListLogsReq struct {
+	Level string `form:"level" binding:"omitempty,oneof=debug info warn error" message:"validation.admin.level"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=1000" message:"validation.admin.limit"`
+}
+
+// ListLogsResp holds log entries as written by the JSON encoder, oldest first
+type ListLogsResp struct {
+	Entries []json.RawMessage `json:"entries"`
+}
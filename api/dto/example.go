@@ -5,8 +5,8 @@ import (
 )
 
 type CreateExampleReq struct {
-	Name  string `json:"name" binding:"required" message:"name is a required field"`
-	Alias string `json:"alias" binding:"required" message:"alias is a required field"`
+	Name  string `json:"name" binding:"required" message:"validation.example.name_required"`
+	Alias string `json:"alias" binding:"required" message:"validation.example.alias_required"`
 }
 
 type CreateExampleResp struct {
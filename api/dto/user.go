@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+)
+
+type RegisterUserReq struct {
+	Name     string `json:"name" binding:"required,username" message:"validation.user.name"`
+	Email    string `json:"email" binding:"required,email" message:"validation.user.email"`
+	Phone    string `json:"phone" binding:"omitempty,phone" message:"validation.user.phone"`
+	Password string `json:"password" binding:"required,password" message:"validation.user.password"`
+}
+
+type UpdateUserReq struct {
+	Name  string `json:"name" binding:"required,username" message:"validation.user.name"`
+	Phone string `json:"phone" binding:"omitempty,phone" message:"validation.user.phone"`
+}
+
+type UserResp struct {
+	Uuid      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
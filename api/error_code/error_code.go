@@ -3,18 +3,35 @@ package error_code
 import (
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
 )
 
 // Error represents a standardized API error
 type Error struct {
-	Code    int      `json:"code"`              // Error code
-	Msg     string   `json:"message"`           // Error message
-	Details []string `json:"details,omitempty"` // Optional error details
-	HTTP    int      `json:"-"`                 // HTTP status code (not exposed in JSON)
-	DocRef  string   `json:"doc_ref,omitempty"` // Reference to documentation
+	Code    int          `json:"code"`              // Error code
+	Msg     string       `json:"message"`           // Error message
+	Details []string     `json:"details,omitempty"` // Optional error details
+	Fields  []FieldError `json:"fields,omitempty"`  // Optional request fields failing validation
+	HTTP    int          `json:"-"`                 // HTTP status code (not exposed in JSON)
+	DocRef  string       `json:"doc_ref,omitempty"` // Reference to documentation
+
+	// customMsg is set by WithMessage, such messages are not replaced by the catalog
+	customMsg bool
+}
+
+// FieldError describes a request field failing a validation rule
+type FieldError struct {
+	Field   string `json:"field"`           // Path of the field in the request, e.g. items[0].name
+	Rule    string `json:"rule"`            // Failed rule, e.g. required or max
+	Param   string `json:"param,omitempty"` // Parameter of the rule, e.g. 64 for max=64
+	Message string `json:"message"`         // Human readable message
 }
 
-var codes = map[int]string{}
+// codes holds the registered errors by code
+var codes = map[int]*Error{}
 
 // Basic error code
 const (
@@ -25,10 +42,18 @@ const (
 	NotFoundCode        = 10002
 	TooManyRequestsCode = 10003
 
+	IdempotencyKeyReusedCode   = 10004
+	IdempotencyKeyInFlightCode = 10005
+
+	RequestTimeoutCode        = 10006
+	ServiceUnavailableCode    = 10007
+	RequestEntityTooLargeCode = 10008
+
 	UnauthorizedAuthNotExistErrorCode  = 20001
 	UnauthorizedTokenErrorCode         = 20002
 	UnauthorizedTokenTimeoutErrorCode  = 20003
 	UnauthorizedTokenGenerateErrorCode = 20004
+	ForbiddenErrorCode                 = 20005
 
 	CopyErrorErrorCode = 30001
 	JSONErrorErrorCode = 30002
@@ -44,6 +69,13 @@ var (
 	InvalidParams   = NewError(InvalidParamsCode, "invalid params")
 	NotFound        = NewError(NotFoundCode, "record not found")
 	TooManyRequests = NewError(TooManyRequestsCode, "too many requests")
+
+	IdempotencyKeyReused   = NewError(IdempotencyKeyReusedCode, "idempotency key already used with a different request")
+	IdempotencyKeyInFlight = NewError(IdempotencyKeyInFlightCode, "a request with this idempotency key is still being processed")
+
+	RequestTimeout        = NewError(RequestTimeoutCode, "request timed out")
+	ServiceUnavailable    = NewError(ServiceUnavailableCode, "service unavailable")
+	RequestEntityTooLarge = NewError(RequestEntityTooLargeCode, "request body too large")
 )
 
 // Auth error code
@@ -52,6 +84,7 @@ var (
 	UnauthorizedTokenError    = NewError(UnauthorizedTokenErrorCode, "unauthorized, token invalid")
 	UnauthorizedTokenTimeout  = NewError(UnauthorizedTokenTimeoutErrorCode, "unauthorized, token timeout")
 	UnauthorizedTokenGenerate = NewError(UnauthorizedTokenGenerateErrorCode, "unauthorized, token generate failed")
+	Forbidden                 = NewError(ForbiddenErrorCode, "forbidden, permission denied")
 )
 
 // Internal error code
@@ -72,12 +105,13 @@ func NewError(code int, msg string) *Error {
 		panic(fmt.Sprintf("error code %d already exists, please replace one", code))
 	}
 
-	codes[code] = msg
-	return &Error{
+	err := &Error{
 		Code: code,
 		Msg:  msg,
 		HTTP: determineHTTPStatusCode(code), // Determine default HTTP status code
 	}
+	codes[code] = err
+	return err
 }
 
 // NewErrorWithStatus creates a new Error instance with the specified code, message, and HTTP status
@@ -86,12 +120,23 @@ func NewErrorWithStatus(code int, msg string, status int) *Error {
 		panic(fmt.Sprintf("error code %d already exists, please replace one", code))
 	}
 
-	codes[code] = msg
-	return &Error{
+	err := &Error{
 		Code: code,
 		Msg:  msg,
 		HTTP: status,
 	}
+	codes[code] = err
+	return err
+}
+
+// Codes returns the registered errors sorted by code
+func Codes() []*Error {
+	errs := make([]*Error, 0, len(codes))
+	for _, err := range codes {
+		errs = append(errs, err)
+	}
+	slices.SortFunc(errs, func(a, b *Error) int { return a.Code - b.Code })
+	return errs
 }
 
 // Error implements the error interface
@@ -113,6 +158,17 @@ func (e *Error) WithDetails(details ...string) *Error {
 	return &newError
 }
 
+// WithFields adds the request fields failing validation, their messages becoming the details
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	newError := *e
+	newError.Fields = fields
+	newError.Details = make([]string, 0, len(fields))
+	for _, field := range fields {
+		newError.Details = append(newError.Details, field.Message)
+	}
+	return &newError
+}
+
 // WithDocRef adds a documentation reference
 func (e *Error) WithDocRef(docRef string) *Error {
 	newError := *e
@@ -120,10 +176,26 @@ func (e *Error) WithDocRef(docRef string) *Error {
 	return &newError
 }
 
-// WithMessage customizes the error message
+// WithMessage customizes the error message, which is then kept in every locale
 func (e *Error) WithMessage(format string, args ...interface{}) *Error {
 	newError := *e
 	newError.Msg = fmt.Sprintf(format, args...)
+	newError.customMsg = true
+	return &newError
+}
+
+// Localize returns a copy of the error with the message of its code in the i18n catalog for locale,
+// falling back to its base language, the default locale and then the message of the error.
+// Messages set by WithMessage are kept.
+func (e *Error) Localize(locale string) *Error {
+	newError := *e
+	if e.customMsg {
+		return &newError
+	}
+
+	if msg, ok := i18n.Message(locale, strconv.Itoa(e.Code)); ok {
+		newError.Msg = msg
+	}
 	return &newError
 }
 
@@ -160,8 +232,19 @@ func determineHTTPStatusCode(code int) int {
 		UnauthorizedTokenGenerateErrorCode,
 		UnauthorizedTokenTimeoutErrorCode:
 		return http.StatusUnauthorized
+	case ForbiddenErrorCode:
+		return http.StatusForbidden
 	case TooManyRequestsCode:
 		return http.StatusTooManyRequests
+	case RequestTimeoutCode:
+		return http.StatusGatewayTimeout
+	case ServiceUnavailableCode:
+		return http.StatusServiceUnavailable
+	case RequestEntityTooLargeCode:
+		return http.StatusRequestEntityTooLarge
+	case AccountExistErrorCode, UserNameExistErrorCode,
+		IdempotencyKeyReusedCode, IdempotencyKeyInFlightCode:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
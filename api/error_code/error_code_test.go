@@ -0,0 +1,33 @@
+package error_code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	assert.Equal(t, "không tìm thấy bản ghi", NotFound.Localize("vi-VN").Msg)
+	assert.Equal(t, "record not found", NotFound.Localize("fr").Msg)
+	// Localizing returns a copy
+	assert.Equal(t, "record not found", NotFound.Msg)
+
+	// Codes missing from the catalog keep their message
+	assert.Equal(t, "order is closed", NewError(99001, "order is closed").Localize("vi").Msg)
+
+	// Custom messages are kept in every locale
+	assert.Equal(t, "Internal server error", ServerError.WithMessage("Internal server error").Localize("vi").Msg)
+
+	localized := InvalidParams.WithFields(FieldError{Field: "name", Rule: "required", Message: "name không được bỏ trống"}).Localize("vi")
+	assert.Equal(t, "tham số không hợp lệ", localized.Msg)
+	assert.Equal(t, []string{"name không được bỏ trống"}, localized.Details)
+}
+
+func TestCodes(t *testing.T) {
+	var numbers []int
+	for _, err := range Codes() {
+		numbers = append(numbers, err.Code)
+	}
+	assert.Contains(t, numbers, NotFoundCode)
+	assert.IsIncreasing(t, numbers)
+}
@@ -0,0 +1,226 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ntdat104/go-clean-architecture/api/dto"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/api/http/validator"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// Admin permissions
+const (
+	// PermLogLevelManage allows reading and changing the log level at runtime
+	PermLogLevelManage authz.Permission = "admin:log_level"
+	// PermLogsRead allows reading the recent entries kept by the log ring buffer
+	PermLogsRead authz.Permission = "admin:logs"
+)
+
+// Bounds of a runtime log level change; every change reverts on its own
+const (
+	DefaultLogLevelDuration = 15 * time.Minute
+	MaxLogLevelDuration     = 24 * time.Hour
+)
+
+// DefaultLogsLimit is the number of ring buffer entries returned when no limit is given
+const DefaultLogsLimit = 100
+
+type AdminHandler interface {
+	GetLogLevel(ctx *gin.Context)
+	SetLogLevel(ctx *gin.Context)
+	ResetLogLevel(ctx *gin.Context)
+	ListLogs(ctx *gin.Context)
+}
+
+type adminHandler struct {
+	router     *gin.Engine
+	levels     *logger.LevelController
+	ring       *logger.RingBuffer
+	authorizer *authz.Authorizer
+}
+
+// NewAdminHandler registers the endpoints of the given levels and ring buffer, skipping nil ones.
+// Nothing is registered without an authorizer: the endpoints are never served unauthenticated.
+func NewAdminHandler(router *gin.Engine, levels *logger.LevelController, ring *logger.RingBuffer, authorizer *authz.Authorizer) {
+	h := &adminHandler{
+		router:     router,
+		levels:     levels,
+		ring:       ring,
+		authorizer: authorizer,
+	}
+	h.initRoutes()
+}
+
+func (h *adminHandler) initRoutes() {
+	if h.authorizer == nil {
+		return
+	}
+	admin := h.router.Group("/admin")
+	if h.levels != nil {
+		logLevel := admin.Group("/log-level", requirePermission(h.authorizer, PermLogLevelManage))
+		{
+			logLevel.GET("", h.GetLogLevel)
+			logLevel.PUT("", h.SetLogLevel)
+			logLevel.DELETE("", h.ResetLogLevel)
+		}
+	}
+	if h.ring != nil {
+		admin.GET("/logs", requirePermission(h.authorizer, PermLogsRead), h.ListLogs)
+	}
+}
+
+// GetLogLevel returns the global level and the component overrides in effect.
+func (h *adminHandler) GetLogLevel(ctx *gin.Context) {
+	handle.NewResponse(ctx).ToResponse(h.levels.State())
+}
+
+// SetLogLevel changes the level globally or for one component until the duration elapses.
+func (h *adminHandler) SetLogLevel(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+	body := dto.SetLogLevelReq{}
+
+	if valid, errs := validator.BindAndValid(ctx, &body, ctx.ShouldBindJSON); !valid {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("SetLogLevel.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errs.ToError())
+		return
+	}
+
+	level, err := logger.ParseLevel(body.Level)
+	if err != nil {
+		response.ToErrorResponse(error_code.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	duration, err := logLevelDuration(body.Duration)
+	if err != nil {
+		response.ToErrorResponse(error_code.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if body.Component == "" {
+		h.levels.SetLevel(level, duration)
+	} else {
+		h.levels.SetComponentLevel(body.Component, level, duration)
+	}
+	logger.SugarForComponent(ctx, logger.ComponentHTTP).Warnf("Log level of %s set to %s for %s", componentName(body.Component), level, duration)
+
+	response.ToResponse(h.levels.State())
+}
+
+// ResetLogLevel reverts the global level, or the component given in the query, to the configured level.
+func (h *adminHandler) ResetLogLevel(ctx *gin.Context) {
+	component := ctx.Query("component")
+	if component == "" {
+		h.levels.ResetLevel()
+	} else {
+		h.levels.ResetComponentLevel(component)
+	}
+	logger.SugarForComponent(ctx, logger.ComponentHTTP).Warnf("Log level of %s reset", componentName(component))
+
+	handle.NewResponse(ctx).ToResponse(h.levels.State())
+}
+
+// ListLogs returns the most recent entries of the ring buffer, oldest first.
+func (h *adminHandler) ListLogs(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+	query := dto.ListLogsReq{}
+
+	if valid, errs := validator.BindAndValid(ctx, &query, ctx.ShouldBindQuery); !valid {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("ListLogs.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errs.ToError())
+		return
+	}
+
+	level := zapcore.DebugLevel
+	if query.Level != "" {
+		level = logger.ParseLogLevel(query.Level)
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultLogsLimit
+	}
+
+	response.ToResponse(dto.ListLogsResp{Entries: h.ring.Entries(level, limit)})
+}
+
+// logLevelDuration parses the requested duration, defaulting to DefaultLogLevelDuration
+func logLevelDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultLogLevelDuration, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < time.Second || duration > MaxLogLevelDuration {
+		return 0, fmt.Errorf("duration must be between 1s and %s", MaxLogLevelDuration)
+	}
+	return duration, nil
+}
+
+// componentName describes the target of a level change in logs
+func componentName(component string) string {
+	if component == "" {
+		return "all components"
+	}
+	return "component " + component
+}
+
+// AdminModule wires the operational endpoints. They are only served when authorization is enabled,
+// so they are never reachable without an admin permission.
+type AdminModule struct{}
+
+func (AdminModule) Name() string {
+	return "admin"
+}
+
+func (AdminModule) Provide(c *container.Container) {
+	container.Provide(c, func(*container.Container) (*logger.LevelController, error) {
+		return logger.Levels, nil
+	})
+	container.Provide(c, func(*container.Container) (*logger.RingBuffer, error) {
+		return logger.Ring, nil
+	})
+}
+
+func (AdminModule) RegisterRoutes(router *gin.Engine, c *container.Container) error {
+	authorizer, err := container.Resolve[*authz.Authorizer](c)
+	if err != nil {
+		return err
+	}
+	levels, err := container.Resolve[*logger.LevelController](c)
+	if err != nil {
+		return err
+	}
+	ring, err := container.Resolve[*logger.RingBuffer](c)
+	if err != nil {
+		return err
+	}
+	if authorizer == nil || (levels == nil && ring == nil) {
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Infof("Admin endpoints disabled: they require authz to be enabled")
+		return nil
+	}
+
+	NewAdminHandler(router, levels, ring, authorizer)
+	return nil
+}
+
+// RouteDocs describes the admin routes in the OpenAPI document
+func (AdminModule) RouteDocs() []RouteDoc {
+	return []RouteDoc{
+		{Method: http.MethodGet, Path: "/admin/log-level", Summary: "Get the log levels in effect",
+			Response: logger.LevelState{}, Errors: permissionErrors},
+		{Method: http.MethodPut, Path: "/admin/log-level", Summary: "Change the log level for a while",
+			Request: dto.SetLogLevelReq{}, Response: logger.LevelState{}, Errors: permissionErrors},
+		{Method: http.MethodDelete, Path: "/admin/log-level", Summary: "Reset the log levels to the configured one",
+			Response: logger.LevelState{}, Errors: permissionErrors},
+		{Method: http.MethodGet, Path: "/admin/logs", Summary: "List the recent log entries",
+			Request: dto.ListLogsReq{}, Response: dto.ListLogsResp{}, Errors: permissionErrors},
+	}
+}
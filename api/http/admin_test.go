@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ntdat104/go-clean-architecture/api/http/middleware"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+func newAdminRouter(t *testing.T, levels *logger.LevelController, ring *logger.RingBuffer) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+
+	authorizer := authz.NewAuthorizer(authz.NewConfigSource(&config.AuthzConfig{
+		Roles: map[string][]string{
+			"admin":  {"*"},
+			"viewer": {"examples:read"},
+		},
+	}))
+	require.NoError(t, authorizer.Reload(context.Background()))
+
+	// httptest requests come from 192.0.2.1, standing for the gateway
+	trusted, err := middleware.NewGatewayTrust([]string{"192.0.2.1"}, "", "")
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(
+		middleware.RequestID(),
+		middleware.AppContextMiddleware(),
+		middleware.Authenticate(middleware.HeaderPrincipalResolver("", "", trusted)),
+	)
+	NewAdminHandler(router, levels, ring, authorizer)
+	return router
+}
+
+func serveAdmin(router *gin.Engine, method, target, roles, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if roles != "" {
+		request.Header.Set(middleware.DefaultSubjectHeader, "operator")
+		request.Header.Set(middleware.DefaultRolesHeader, roles)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAdminLogLevelRequiresPermission(t *testing.T) {
+	levels := logger.NewLevelController(zapcore.InfoLevel)
+	router := newAdminRouter(t, levels, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, serveAdmin(router, http.MethodGet, "/admin/log-level", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveAdmin(router, http.MethodPut, "/admin/log-level", "viewer", `{"level":"debug"}`).Code)
+	assert.Equal(t, zapcore.InfoLevel, levels.Level())
+}
+
+func TestAdminRejectsForgedIdentity(t *testing.T) {
+	levels := logger.NewLevelController(zapcore.InfoLevel)
+	ring := logger.NewRingBuffer(10)
+	router := newAdminRouter(t, levels, ring)
+
+	// Identity headers sent by a client rather than the gateway are ignored
+	for _, target := range []string{"/admin/log-level", "/admin/logs"} {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.RemoteAddr = "203.0.113.7:4321"
+		request.Header.Set(middleware.DefaultSubjectHeader, "operator")
+		request.Header.Set(middleware.DefaultRolesHeader, "admin")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, target)
+	}
+
+	// The endpoints are not served at all without an authorizer
+	unprotected := gin.New()
+	NewAdminHandler(unprotected, levels, ring, nil)
+	assert.Empty(t, unprotected.Routes())
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	levels := logger.NewLevelController(zapcore.InfoLevel)
+	router := newAdminRouter(t, levels, nil)
+
+	recorder := serveAdmin(router, http.MethodPut, "/admin/log-level", "admin", `{"level":"debug","component":"repo","duration":"10m"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, levels.Enabled("repo", zapcore.DebugLevel))
+	assert.False(t, levels.Enabled("http", zapcore.DebugLevel))
+
+	recorder = serveAdmin(router, http.MethodPut, "/admin/log-level", "admin", `{"level":"warn"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, zapcore.WarnLevel, levels.Level())
+
+	var body struct {
+		Data logger.LevelState `json:"data"`
+	}
+	recorder = serveAdmin(router, http.MethodGet, "/admin/log-level", "admin", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "warn", body.Data.Level)
+	assert.Equal(t, "info", body.Data.Configured)
+	// Changes without a duration still revert after the default duration
+	assert.NotNil(t, body.Data.ExpiresAt)
+	assert.Equal(t, "debug", body.Data.Components["repo"].Level)
+
+	for _, invalid := range []string{
+		`{"level":"verbose"}`,
+		`{"level":"debug","duration":"48h"}`,
+		`{"level":"debug","duration":"500ms"}`,
+		`{"level":"debug","duration":"soon"}`,
+	} {
+		recorder = serveAdmin(router, http.MethodPut, "/admin/log-level", "admin", invalid)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, invalid)
+	}
+
+	recorder = serveAdmin(router, http.MethodDelete, "/admin/log-level?component=repo", "admin", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = serveAdmin(router, http.MethodDelete, "/admin/log-level", "admin", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, zapcore.InfoLevel, levels.Level())
+	assert.Empty(t, levels.State().Components)
+}
+
+func TestAdminLogs(t *testing.T) {
+	ring := logger.NewRingBuffer(10)
+	router := newAdminRouter(t, nil, ring)
+	for i, level := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel} {
+		require.NoError(t, ring.WriteEntry(zapcore.Entry{Level: level}, fmt.Appendf(nil, "{\"n\":%d}\n", i)))
+	}
+
+	assert.Equal(t, http.StatusForbidden, serveAdmin(router, http.MethodGet, "/admin/logs", "viewer", "").Code)
+	// Without levels the log level endpoints are not served
+	assert.Equal(t, http.StatusNotFound, serveAdmin(router, http.MethodGet, "/admin/log-level", "admin", "").Code)
+
+	var body struct {
+		Data struct {
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"data"`
+	}
+	recorder := serveAdmin(router, http.MethodGet, "/admin/logs?level=info&limit=2", "admin", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Len(t, body.Data.Entries, 2)
+	assert.JSONEq(t, `{"n":2}`, string(body.Data.Entries[0]))
+	assert.JSONEq(t, `{"n":3}`, string(body.Data.Entries[1]))
+
+	assert.Equal(t, http.StatusBadRequest, serveAdmin(router, http.MethodGet, "/admin/logs?limit=5000", "admin", "").Code)
+}
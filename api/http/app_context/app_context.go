@@ -4,12 +4,21 @@ import (
 	"context"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
 	"go.uber.org/zap"
 )
 
+const (
+	// ContextKey is the gin.Context key holding the AppContext
+	ContextKey = "app_context"
+	// RequestIDKey is the gin.Context key (and header name) holding the request ID
+	RequestIDKey = "X-Request-ID"
+)
+
 type AppContext struct {
-	Ctx    context.Context
-	Logger *zap.Logger
+	Ctx       context.Context
+	Logger    *zap.Logger
+	Principal *authz.Principal
 }
 
 func (a *AppContext) Cleanup() {
@@ -17,7 +26,7 @@ func (a *AppContext) Cleanup() {
 }
 
 func Get(c *gin.Context) *AppContext {
-	val, exists := c.Get("app_context")
+	val, exists := c.Get(ContextKey)
 	if !exists {
 		c.JSON(500, gin.H{"error": "app_context not found in gin.Context — did you forget the middleware?"})
 		return nil
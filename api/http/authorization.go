@@ -0,0 +1,51 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// requirePermission aborts the request unless the principal in the AppContext holds perm.
+// A nil authorizer means authorization is disabled and every request passes.
+func requirePermission(authorizer *authz.Authorizer, perm authz.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if authorizer == nil {
+			ctx.Next()
+			return
+		}
+
+		var principal *authz.Principal
+		if appCtx := app_context.Get(ctx); appCtx != nil {
+			principal = appCtx.Principal
+		}
+
+		if err := authorizer.Check(principal, perm); err != nil {
+			logger.SugarForComponent(ctx, logger.ComponentHTTP).Warnf("requirePermission denied %s %s: %v", ctx.Request.Method, ctx.FullPath(), err)
+			handle.NewResponse(ctx).ToErrorResponse(handle.FromAppError(err, error_code.Forbidden))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// requireOwnerOrPermission aborts the request unless the principal is the user named by the path
+// parameter param, or holds perm. It must follow requirePermission, which rejects anonymous requests.
+func requireOwnerOrPermission(authorizer *authz.Authorizer, param string, perm authz.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var principal *authz.Principal
+		if appCtx := app_context.Get(ctx); appCtx != nil {
+			principal = appCtx.Principal
+		}
+		if principal != nil && principal.Subject == ctx.Param(param) {
+			ctx.Next()
+			return
+		}
+		requirePermission(authorizer, perm)(ctx)
+	}
+}
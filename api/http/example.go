@@ -1,14 +1,22 @@
 package http
 
 import (
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
 	"github.com/ntdat104/go-clean-architecture/api/dto"
 	"github.com/ntdat104/go-clean-architecture/api/error_code"
 	"github.com/ntdat104/go-clean-architecture/api/http/handle"
 	"github.com/ntdat104/go-clean-architecture/api/http/validator"
 	"github.com/ntdat104/go-clean-architecture/application/service"
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+	domainRepo "github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/infra/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
 )
 
@@ -23,12 +31,14 @@ type ExampleHandler interface {
 type exampleHandler struct {
 	router         *gin.Engine
 	exampleService service.IExampleService
+	authorizer     *authz.Authorizer
 }
 
-func NewExampleHandler(router *gin.Engine, exampleService service.IExampleService) {
+func NewExampleHandler(router *gin.Engine, exampleService service.IExampleService, authorizer *authz.Authorizer) {
 	h := &exampleHandler{
 		router:         router,
 		exampleService: exampleService,
+		authorizer:     authorizer,
 	}
 	h.initRoutes()
 }
@@ -36,11 +46,14 @@ func NewExampleHandler(router *gin.Engine, exampleService service.IExampleServic
 func (h *exampleHandler) initRoutes() {
 	v1 := h.router.Group("/api/v1/examples")
 	{
-		v1.POST("", h.Create)
-		v1.GET("/:id", h.Get)
-		v1.PUT("/:id", h.Update)
-		v1.DELETE("/:id", h.Delete)
-		v1.GET("/name/:name", h.FindByName)
+		read := requirePermission(h.authorizer, service.PermExamplesRead)
+		write := requirePermission(h.authorizer, service.PermExamplesWrite)
+
+		v1.POST("", write, h.Create)
+		v1.GET("/:id", read, h.Get)
+		v1.PUT("/:id", write, h.Update)
+		v1.DELETE("/:id", write, h.Delete)
+		v1.GET("/name/:name", read, h.FindByName)
 	}
 }
 
@@ -50,16 +63,16 @@ func (h *exampleHandler) Create(ctx *gin.Context) {
 	body := dto.CreateExampleReq{}
 
 	if valid, errs := validator.BindAndValid(ctx, &body, ctx.ShouldBindJSON); !valid {
-		logger.SugaredLogger.Errorf("Create.BindAndValid errs: %v", errs)
-		err := error_code.InvalidParams.WithDetails(errs.Errors()...)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Create.BindAndValid errs: %v", errs)
+		err := errs.ToError()
 		response.ToErrorResponse(err)
 		return
 	}
 
 	createdExample, err := h.exampleService.Create(ctx, body.Name, body.Alias)
 	if err != nil {
-		logger.SugaredLogger.Errorf("Create.exampleService.Create err: %v", err)
-		response.ToErrorResponse(error_code.ServerError)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Create.exampleService.Create err: %v", err)
+		response.ToErrorResponse(handle.FromAppError(err, error_code.ServerError))
 		return
 	}
 
@@ -71,15 +84,15 @@ func (h *exampleHandler) Get(ctx *gin.Context) {
 	response := handle.NewResponse(ctx)
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		logger.SugaredLogger.Errorf("Get.Invalid ID: %v", err)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Get.Invalid ID: %v", err)
 		response.ToErrorResponse(error_code.InvalidParams.WithDetails("invalid id"))
 		return
 	}
 
 	example, err := h.exampleService.Get(ctx, id)
 	if err != nil {
-		logger.SugaredLogger.Errorf("Get.exampleService.Get err: %v", err)
-		response.ToErrorResponse(error_code.ServerError)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Get.exampleService.Get err: %v", err)
+		response.ToErrorResponse(handle.FromAppError(err, error_code.ServerError))
 		return
 	}
 
@@ -93,8 +106,8 @@ func (h *exampleHandler) FindByName(ctx *gin.Context) {
 
 	example, err := h.exampleService.FindByName(ctx, name)
 	if err != nil {
-		logger.SugaredLogger.Errorf("FindByName.exampleService.FindByName err: %v", err)
-		response.ToErrorResponse(error_code.ServerError)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("FindByName.exampleService.FindByName err: %v", err)
+		response.ToErrorResponse(handle.FromAppError(err, error_code.ServerError))
 		return
 	}
 
@@ -106,22 +119,22 @@ func (h *exampleHandler) Update(ctx *gin.Context) {
 	response := handle.NewResponse(ctx)
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		logger.SugaredLogger.Errorf("Update.Invalid ID: %v", err)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Update.Invalid ID: %v", err)
 		response.ToErrorResponse(error_code.InvalidParams.WithDetails("invalid id"))
 		return
 	}
 
 	body := dto.UpdateExampleReq{}
 	if valid, errs := validator.BindAndValid(ctx, &body, ctx.ShouldBindJSON); !valid {
-		logger.SugaredLogger.Errorf("Update.BindAndValid errs: %v", errs)
-		err := error_code.InvalidParams.WithDetails(errs.Errors()...)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Update.BindAndValid errs: %v", errs)
+		err := errs.ToError()
 		response.ToErrorResponse(err)
 		return
 	}
 
 	if err := h.exampleService.Update(ctx, id, body.Name, body.Alias); err != nil {
-		logger.SugaredLogger.Errorf("Update.exampleService.Update err: %v", err)
-		response.ToErrorResponse(error_code.ServerError)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Update.exampleService.Update err: %v", err)
+		response.ToErrorResponse(handle.FromAppError(err, error_code.ServerError))
 		return
 	}
 
@@ -133,16 +146,85 @@ func (h *exampleHandler) Delete(ctx *gin.Context) {
 	response := handle.NewResponse(ctx)
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		logger.SugaredLogger.Errorf("Delete.Invalid ID: %v", err)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Delete.Invalid ID: %v", err)
 		response.ToErrorResponse(error_code.InvalidParams.WithDetails("invalid id"))
 		return
 	}
 
 	if err := h.exampleService.Delete(ctx, id); err != nil {
-		logger.SugaredLogger.Errorf("Delete.exampleService.Delete err: %v", err)
-		response.ToErrorResponse(error_code.ServerError)
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Delete.exampleService.Delete err: %v", err)
+		response.ToErrorResponse(handle.FromAppError(err, error_code.ServerError))
 		return
 	}
 
 	response.ToSuccess()
 }
+
+// ExampleModule wires the example feature
+type ExampleModule struct{}
+
+func (ExampleModule) Name() string {
+	return "example"
+}
+
+func (ExampleModule) Provide(c *container.Container) {
+	container.Provide(c, func(c *container.Container) (domainRepo.IExampleRepo, error) {
+		db, err := container.Resolve[*sqlx.DB](c)
+		if err != nil {
+			return nil, err
+		}
+		return repo.NewExampleRepo(db), nil
+	})
+	container.Provide(c, func(c *container.Container) (domainRepo.IExampleCacheRepo, error) {
+		rdb, err := resolveOptional[*redis.Client](c)
+		if err != nil || rdb == nil {
+			// Without Redis the service reads from the database only
+			return nil, err
+		}
+		return repo.NewExampleCacheRepo(rdb), nil
+	})
+	container.Provide(c, func(c *container.Container) (service.IExampleService, error) {
+		exampleRepo, err := container.Resolve[domainRepo.IExampleRepo](c)
+		if err != nil {
+			return nil, err
+		}
+		exampleCacheRepo, err := container.Resolve[domainRepo.IExampleCacheRepo](c)
+		if err != nil {
+			return nil, err
+		}
+		authorizer, err := container.Resolve[*authz.Authorizer](c)
+		if err != nil {
+			return nil, err
+		}
+		return service.NewExampleService(exampleRepo, exampleCacheRepo, authorizer), nil
+	})
+}
+
+func (ExampleModule) RegisterRoutes(router *gin.Engine, c *container.Container) error {
+	exampleService, err := container.Resolve[service.IExampleService](c)
+	if err != nil {
+		return err
+	}
+	authorizer, err := container.Resolve[*authz.Authorizer](c)
+	if err != nil {
+		return err
+	}
+	NewExampleHandler(router, exampleService, authorizer)
+	return nil
+}
+
+// RouteDocs describes the example routes in the OpenAPI document
+func (ExampleModule) RouteDocs() []RouteDoc {
+	return []RouteDoc{
+		{Method: http.MethodPost, Path: "/api/v1/examples", Summary: "Create an example",
+			Request: dto.CreateExampleReq{}, Response: model.Example{}, Errors: permissionErrors},
+		{Method: http.MethodGet, Path: "/api/v1/examples/:id", Summary: "Get an example by ID",
+			Request: dto.GetExampleReq{}, Response: model.Example{}, Errors: permissionErrors},
+		{Method: http.MethodPut, Path: "/api/v1/examples/:id", Summary: "Update an example",
+			Request: dto.UpdateExampleReq{}, Errors: permissionErrors},
+		{Method: http.MethodDelete, Path: "/api/v1/examples/:id", Summary: "Delete an example",
+			Request: dto.DeleteExampleReq{}, Errors: permissionErrors},
+		{Method: http.MethodGet, Path: "/api/v1/examples/name/:name", Summary: "Find an example by name",
+			Response: model.Example{}, Errors: permissionErrors},
+	}
+}
@@ -8,9 +8,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntdat104/go-clean-architecture/api/error_code"
-	"github.com/ntdat104/go-clean-architecture/api/http/middleware"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
 	"github.com/ntdat104/go-clean-architecture/api/http/paginate"
+	"github.com/ntdat104/go-clean-architecture/pkg/errors"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
 )
 
 type Response struct {
@@ -19,6 +22,7 @@ type Response struct {
 
 type Meta struct {
 	RequestID string `json:"request_id"`
+	TraceID   string `json:"trace_id,omitempty"`
 	Timestamp int64  `json:"timestamp"`
 	Datetime  string `json:"datetime"`
 	Code      int    `json:"code"`
@@ -32,9 +36,10 @@ type Meta struct {
 
 // StandardResponse defines the standard API response structure
 type StandardResponse struct {
-	Meta   Meta     `json:"meta"`
-	Data   any      `json:"data,omitempty"`
-	Errors []string `json:"errors,omitempty"`
+	Meta   Meta                    `json:"meta"`
+	Data   any                     `json:"data,omitempty"`
+	Errors []string                `json:"errors,omitempty"`
+	Fields []error_code.FieldError `json:"fields,omitempty"`
 }
 
 func NewResponse(ctx *gin.Context) *Response {
@@ -45,11 +50,12 @@ func (r *Response) ToSuccess() {
 	now := time.Now()
 	r.Ctx.JSON(http.StatusOK, StandardResponse{
 		Meta: Meta{
-			RequestID: r.Ctx.GetString(middleware.RequestIDHeader),
+			RequestID: r.Ctx.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(r.Ctx.Request.Context()),
 			Timestamp: now.UnixMilli(),
 			Datetime:  now.Format("2006-01-02 15:04:05"),
 			Code:      error_code.SuccessCode,
-			Message:   "success",
+			Message:   successMessage(r.Ctx),
 		},
 	})
 }
@@ -58,11 +64,12 @@ func (r *Response) ToResponse(data interface{}) {
 	now := time.Now()
 	r.Ctx.JSON(http.StatusOK, StandardResponse{
 		Meta: Meta{
-			RequestID: r.Ctx.GetString(middleware.RequestIDHeader),
+			RequestID: r.Ctx.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(r.Ctx.Request.Context()),
 			Timestamp: now.UnixMilli(),
 			Datetime:  now.Format("2006-01-02 15:04:05"),
 			Code:      error_code.SuccessCode,
-			Message:   "success",
+			Message:   successMessage(r.Ctx),
 		},
 		Data: data,
 	})
@@ -72,11 +79,12 @@ func (r *Response) ToResponseList(data interface{}, totalRows int) {
 	now := time.Now()
 	r.Ctx.JSON(http.StatusOK, StandardResponse{
 		Meta: Meta{
-			RequestID: r.Ctx.GetString(middleware.RequestIDHeader),
+			RequestID: r.Ctx.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(r.Ctx.Request.Context()),
 			Timestamp: now.UnixMilli(),
 			Datetime:  now.Format("2006-01-02 15:04:05"),
 			Code:      error_code.SuccessCode,
-			Message:   "success",
+			Message:   successMessage(r.Ctx),
 			Page:      paginate.GetPage(r.Ctx),
 			PageSize:  paginate.GetPageSize(r.Ctx),
 			Total:     totalRows,
@@ -85,11 +93,20 @@ func (r *Response) ToResponseList(data interface{}, totalRows int) {
 	})
 }
 
+// ToErrorResponse writes err with its message in the locale of the request, in the StandardResponse
+// envelope or as RFC 7807 problem details when WantsProblem selects them
 func (r *Response) ToErrorResponse(err *error_code.Error) {
+	err = err.Localize(i18n.RequestLocale(r.Ctx.Request))
+	if WantsProblem(r.Ctx) {
+		toProblem(r.Ctx, err)
+		return
+	}
+
 	now := time.Now()
 	r.Ctx.JSON(err.StatusCode(), StandardResponse{
 		Meta: Meta{
-			RequestID: r.Ctx.GetString(middleware.RequestIDHeader),
+			RequestID: r.Ctx.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(r.Ctx.Request.Context()),
 			Timestamp: now.UnixMilli(),
 			Datetime:  now.Format("2006-01-02 15:04:05"),
 			Code:      err.Code,
@@ -97,6 +114,7 @@ func (r *Response) ToErrorResponse(err *error_code.Error) {
 			DocRef:    err.DocRef,
 		},
 		Errors: err.Details,
+		Fields: err.Fields,
 	})
 }
 
@@ -105,49 +123,53 @@ func Success(c *gin.Context, data any) {
 	now := time.Now()
 	c.JSON(http.StatusOK, StandardResponse{
 		Meta: Meta{
-			RequestID: c.GetString(middleware.RequestIDHeader),
+			RequestID: c.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(c.Request.Context()),
 			Timestamp: now.UnixMilli(),
 			Datetime:  now.Format("2006-01-02 15:04:05"),
 			Code:      error_code.SuccessCode,
-			Message:   "success",
+			Message:   successMessage(c),
 		},
 		Data: data,
 	})
 }
 
-// Error unified error handling
+// Error unified error handling, rendered as ToErrorResponse does
 func Error(c *gin.Context, err error) {
-	now := time.Now()
-
 	// Handle API error codes
 	if apiErr, ok := err.(*error_code.Error); ok {
-		c.JSON(apiErr.StatusCode(), StandardResponse{
-			Meta: Meta{
-				RequestID: c.GetString(middleware.RequestIDHeader),
-				Timestamp: now.UnixMilli(),
-				Datetime:  now.Format("2006-01-02 15:04:05"),
-				Code:      apiErr.Code,
-				Message:   apiErr.Msg,
-				DocRef:    apiErr.DocRef,
-			},
-			Errors: apiErr.Details,
-		})
+		NewResponse(c).ToErrorResponse(apiErr)
+		return
+	}
+
+	// Handle application errors with a dedicated API error code
+	if apiErr := FromAppError(err, nil); apiErr != nil {
+		Error(c, apiErr)
 		return
 	}
 
 	// Log unexpected errors
-	logger.SugaredLogger.Errorf("Unexpected error: %v", err)
+	logger.SugarForComponent(c, logger.ComponentHTTP).Errorf("Unexpected error: %v", err)
 
 	// Default error response
-	c.JSON(http.StatusInternalServerError, StandardResponse{
-		Meta: Meta{
-			RequestID: c.GetString(middleware.RequestIDHeader),
-			Timestamp: now.UnixMilli(),
-			Datetime:  now.Format("2006-01-02 15:04:05"),
-			Code:      error_code.ServerErrorCode,
-			Message:   "Internal server error",
-		},
-	})
+	NewResponse(c).ToErrorResponse(error_code.ServerError)
+}
+
+// successMessage returns the message of successful responses in the locale of the request
+func successMessage(c *gin.Context) string {
+	return error_code.Success.Localize(i18n.RequestLocale(c.Request)).Msg
+}
+
+// FromAppError maps an application error to its API error code, returning fallback for other errors
+func FromAppError(err error, fallback *error_code.Error) *error_code.Error {
+	switch {
+	case errors.IsForbiddenError(err):
+		return error_code.Forbidden
+	case errors.IsUnauthorizedError(err):
+		return error_code.UnauthorizedAuthNotExist
+	default:
+		return fallback
+	}
 }
 
 // GetQueryInt gets an integer from query parameters with a default value
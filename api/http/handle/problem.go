@@ -0,0 +1,78 @@
+package handle
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ErrorFormatProblem selects problem details in the http_server.error_format setting
+const ErrorFormatProblem = "problem"
+
+// ProblemTypeBlank is the problem type of errors without a documentation reference
+const ProblemTypeBlank = "about:blank"
+
+// Problem is an RFC 7807 problem details object. Code, RequestID, TraceID, Errors and Fields are
+// extension members carrying what the StandardResponse envelope reports in meta, errors and fields.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      int                     `json:"code"`
+	RequestID string                  `json:"request_id,omitempty"`
+	TraceID   string                  `json:"trace_id,omitempty"`
+	Errors    []string                `json:"errors,omitempty"`
+	Fields    []error_code.FieldError `json:"fields,omitempty"`
+}
+
+// NewProblem describes err as problem details of the request in c.
+// The type is the documentation reference of err, the title its message and the instance the request URI.
+func NewProblem(c *gin.Context, err *error_code.Error) Problem {
+	problemType := err.DocRef
+	if problemType == "" {
+		problemType = ProblemTypeBlank
+	}
+
+	return Problem{
+		Type:      problemType,
+		Title:     err.Msg,
+		Status:    err.StatusCode(),
+		Detail:    strings.Join(err.Details, "; "),
+		Instance:  c.Request.URL.RequestURI(),
+		Code:      err.Code,
+		RequestID: c.GetString(app_context.RequestIDKey),
+		TraceID:   tracing.TraceID(c.Request.Context()),
+		Errors:    err.Details,
+		Fields:    err.Fields,
+	}
+}
+
+// WantsProblem reports whether errors of the request are rendered as problem details,
+// either because the client accepts them or because http_server.error_format selects them
+func WantsProblem(c *gin.Context) bool {
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(accept); err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+
+	conf := config.Get()
+	return conf != nil && conf.HTTPServer != nil && conf.HTTPServer.ErrorFormat == ErrorFormatProblem
+}
+
+// toProblem writes err as problem details
+func toProblem(c *gin.Context, err *error_code.Error) {
+	// gin keeps a content type set beforehand
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(err.StatusCode(), NewProblem(c, err))
+}
@@ -0,0 +1,86 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+func serveError(t *testing.T, accept string, err error) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/examples/:id", func(c *gin.Context) {
+		c.Set(app_context.RequestIDKey, "req-1")
+		Error(c, err)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/examples/7?expand=true", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestErrorDefaultsToStandardResponse(t *testing.T) {
+	w := serveError(t, "application/json", error_code.NotFound)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var body StandardResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, error_code.NotFoundCode, body.Meta.Code)
+	assert.Equal(t, "req-1", body.Meta.RequestID)
+}
+
+func TestErrorRendersProblemWhenAccepted(t *testing.T) {
+	apiErr := error_code.InvalidParams.WithDetails("name is required", "age must be positive").WithDocRef("https://docs.example.com/errors/invalid-params")
+	w := serveError(t, "application/json;q=0.5, application/problem+json", apiErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, map[string]any{
+		"type":       "https://docs.example.com/errors/invalid-params",
+		"title":      "invalid params",
+		"status":     float64(http.StatusBadRequest),
+		"detail":     "name is required; age must be positive",
+		"instance":   "/examples/7?expand=true",
+		"code":       float64(error_code.InvalidParamsCode),
+		"request_id": "req-1",
+		"errors":     []any{"name is required", "age must be positive"},
+	}, problem)
+}
+
+func TestErrorRendersProblemWhenConfigured(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	originalConfig := config.GlobalConfig
+	defer func() { config.GlobalConfig = originalConfig }()
+	config.GlobalConfig = &config.Config{HTTPServer: &config.HttpServerConfig{ErrorFormat: ErrorFormatProblem}}
+
+	w := serveError(t, "", errors.New("boom"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, ProblemTypeBlank, problem.Type)
+	assert.Equal(t, "server internal error", problem.Title)
+	assert.Equal(t, error_code.ServerErrorCode, problem.Code)
+	assert.Empty(t, problem.Detail)
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"time"
 
@@ -21,13 +22,19 @@ func (w bodyLogWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// ZapLoggerWithBody logs every request with its headers and bodies, masking secrets with the configured redactor
 func ZapLoggerWithBody() gin.HandlerFunc {
+	redactor := redactorFromGlobal()
+
 	return func(c *gin.Context) {
 		start := time.Now()
 
+		// Bodies are only logged for sampled requests
+		logBodies := redactor.SampleBody()
+
 		// Clone the request body
 		var reqBody []byte
-		if c.Request.Body != nil {
+		if logBodies && c.Request.Body != nil {
 			reqBody, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBody)) // restore
 		}
@@ -46,25 +53,33 @@ func ZapLoggerWithBody() gin.HandlerFunc {
 
 		fields := []zap.Field{
 			zap.String("request_id", c.GetHeader(RequestIDHeader)),
-			zap.String("app_name", config.GlobalConfig.App.Name),
-			zap.String("app_version", config.GlobalConfig.App.Version),
+			zap.String("app_name", config.Get().App.Name),
+			zap.String("app_version", config.Get().App.Version),
 			zap.String("start_time", formattedStart),
 			zap.String("end_time", formattedEnd),
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
-			zap.String("x_api_key", c.GetHeader("X-Api-Key")),
-			zap.String("x_api_secret", c.GetHeader("X-Api-Secret")),
-			zap.String("access_token", c.GetHeader("Authorization")),
-			zap.String("signature", c.GetHeader("Signature")),
+			zap.String("x_api_key", redactor.Header("X-Api-Key", c.GetHeader("X-Api-Key"))),
+			zap.String("x_api_secret", redactor.Header("X-Api-Secret", c.GetHeader("X-Api-Secret"))),
+			zap.String("access_token", redactor.Header("Authorization", c.GetHeader("Authorization"))),
+			zap.String("signature", redactor.Header("Signature", c.GetHeader("Signature"))),
 			zap.Int("status", c.Writer.Status()),
 			zap.String("client_ip", c.ClientIP()),
 			zap.String("user_agent", c.Request.UserAgent()),
 			zap.String("latency", duration.String()),
-			zap.ByteString("request_body", reqBody),
-			zap.ByteString("response_body", blw.body.Bytes()),
+		}
+
+		if headers := redactor.Headers(c.Request.Header); len(headers) > 0 {
+			fields = append(fields, zap.Any("headers", headers))
+		}
+		if body, ok := redactor.Body(c.GetHeader("Content-Type"), reqBody); ok {
+			fields = append(fields, zap.String("request_body", body))
+		}
+		if body, ok := redactor.Body(blw.Header().Get("Content-Type"), blw.body.Bytes()); logBodies && ok {
+			fields = append(fields, zap.String("response_body", body))
 		}
 
 		// Write structured log
-		logger.Logger.Info("HTTP request", fields...)
+		logger.ForComponent(context.Background(), logger.ComponentHTTP).Info("HTTP request", fields...)
 	}
 }
@@ -4,17 +4,28 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
-	"go.uber.org/zap"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
 )
 
+// AppContextMiddleware attaches the AppContext and stores a request-scoped logger in the request context,
+// so services and repositories log with the request ID and the trace started by the tracing middleware
 func AppContextMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		logContext := &logger.LogContext{
+			RequestID: c.GetString(app_context.RequestIDKey),
+			TraceID:   tracing.TraceID(ctx),
+			SpanID:    tracing.SpanID(ctx),
+		}
+		ctx = logger.WithLogContext(ctx, logContext)
+		c.Request = c.Request.WithContext(ctx)
+
 		appCtx := &app_context.AppContext{
-			Ctx:    c.Request.Context(),
-			Logger: logger.Logger.With(zap.String("request_id", c.GetString("X-Request-ID"))),
+			Ctx:    ctx,
+			Logger: logger.FromContext(ctx),
 		}
 		defer appCtx.Cleanup()
-		c.Set("app_context", appCtx)
+		c.Set(app_context.ContextKey, appCtx)
 		c.Next()
 	}
 }
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+func TestRequestScopedLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := logger.Logger
+	t.Cleanup(func() { logger.Logger = previous })
+	core, logs := observer.New(zap.DebugLevel)
+	logger.Logger = zap.New(core)
+
+	// Stands in for a service method receiving the request context
+	service := func(ctx context.Context) {
+		logger.SugarFromContext(ctx).Infof("service called")
+	}
+
+	trusted, err := NewGatewayTrust([]string{"192.0.2.0/24"}, "", "")
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(RequestID(), AppContextMiddleware(), Authenticate(HeaderPrincipalResolver("", "", trusted)))
+	router.GET("/", func(c *gin.Context) {
+		service(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "req-42")
+	request.Header.Set(DefaultSubjectHeader, "alice")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	entries := logs.FilterMessage("service called").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "req-42", fields["request_id"])
+	assert.Equal(t, "alice", fields["user_id"])
+}
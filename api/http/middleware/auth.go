@@ -1,11 +1,29 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
 )
 
+// Default headers carrying the identity asserted by an upstream authenticating gateway,
+// and the secret proving a request comes from it
+const (
+	DefaultSubjectHeader = "X-User-ID"
+	DefaultRolesHeader   = "X-User-Roles"
+	DefaultSecretHeader  = "X-Gateway-Secret"
+)
+
+// PrincipalResolver extracts the authenticated principal from a request, returning nil when anonymous
+type PrincipalResolver func(c *gin.Context) *authz.Principal
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
@@ -17,3 +35,104 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// GatewayTrust reports whether a request comes from the authenticating gateway, whose identity headers are trusted
+type GatewayTrust func(c *gin.Context) bool
+
+// NewGatewayTrust trusts the requests whose peer address, not a forwarded one, is one of proxies,
+// addresses or CIDR networks, and the requests carrying secret in secretHeader.
+// It trusts no request when both are empty.
+func NewGatewayTrust(proxies []string, secretHeader, secret string) (GatewayTrust, error) {
+	if secretHeader == "" {
+		secretHeader = DefaultSecretHeader
+	}
+
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		cidr := proxy
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return func(c *gin.Context) bool {
+		if secret != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(secretHeader)), []byte(secret)) == 1 {
+			return true
+		}
+		host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil {
+			host = c.Request.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// HeaderPrincipalResolver resolves the principal from gateway supplied subject and comma separated roles headers.
+// Requests that trusted rejects are anonymous, their identity headers are ignored.
+func HeaderPrincipalResolver(subjectHeader, rolesHeader string, trusted GatewayTrust) PrincipalResolver {
+	if subjectHeader == "" {
+		subjectHeader = DefaultSubjectHeader
+	}
+	if rolesHeader == "" {
+		rolesHeader = DefaultRolesHeader
+	}
+
+	return func(c *gin.Context) *authz.Principal {
+		subject := c.GetHeader(subjectHeader)
+		if subject == "" {
+			return nil
+		}
+		if trusted == nil || !trusted(c) {
+			// Any client can send these headers: Debug only, and without the client-supplied subject
+			logger.SugarForComponent(c, logger.ComponentHTTP).Debugf("Ignoring identity headers from untrusted peer %s", c.Request.RemoteAddr)
+			return nil
+		}
+
+		var roles []string
+		for _, role := range strings.Split(c.GetHeader(rolesHeader), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		return &authz.Principal{Subject: subject, Roles: roles}
+	}
+}
+
+// Authenticate attaches the resolved principal to the AppContext and the request context,
+// and adds its subject as user_id to the request logger.
+// Anonymous requests pass through; routes enforce access with a permission check.
+func Authenticate(resolve PrincipalResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := resolve(c)
+		if principal == nil {
+			c.Next()
+			return
+		}
+
+		ctx := authz.WithPrincipal(c.Request.Context(), principal)
+		ctx = logger.WithLogContext(ctx, &logger.LogContext{UserID: principal.Subject})
+		c.Request = c.Request.WithContext(ctx)
+
+		if appCtx := app_context.Get(c); appCtx != nil {
+			appCtx.Principal = principal
+			appCtx.Ctx = ctx
+			appCtx.Logger = logger.FromContext(ctx)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// resolvePrincipal resolves the principal of a request from remoteAddr with the given headers
+func resolvePrincipal(t *testing.T, trusted GatewayTrust, remoteAddr string, headers map[string]string) *authz.Principal {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = request
+	return HeaderPrincipalResolver("", "", trusted)(c)
+}
+
+func TestHeaderPrincipalResolverTrustsGatewayOnly(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	trusted, err := NewGatewayTrust([]string{"10.0.0.0/8", "2001:db8::1"}, "", "s3cret")
+	require.NoError(t, err)
+
+	identity := map[string]string{DefaultSubjectHeader: "alice", DefaultRolesHeader: "admin, viewer"}
+	assert.Equal(t, &authz.Principal{Subject: "alice", Roles: []string{"admin", "viewer"}},
+		resolvePrincipal(t, trusted, "10.1.2.3:5000", identity))
+	assert.NotNil(t, resolvePrincipal(t, trusted, "[2001:db8::1]:5000", identity))
+
+	// Forged headers from any other peer are ignored, whatever the forwarded address
+	forged := map[string]string{DefaultSubjectHeader: "mallory", DefaultRolesHeader: "admin", "X-Forwarded-For": "10.1.2.3"}
+	assert.Nil(t, resolvePrincipal(t, trusted, "203.0.113.7:5000", forged))
+	forged[DefaultSecretHeader] = "guess"
+	assert.Nil(t, resolvePrincipal(t, trusted, "203.0.113.7:5000", forged))
+
+	// The shared secret proves the request comes from the gateway
+	forged[DefaultSecretHeader] = "s3cret"
+	assert.NotNil(t, resolvePrincipal(t, trusted, "203.0.113.7:5000", forged))
+
+	// Without trust nothing is accepted
+	assert.Nil(t, resolvePrincipal(t, nil, "10.1.2.3:5000", identity))
+	none, err := NewGatewayTrust(nil, "", "")
+	require.NoError(t, err)
+	assert.Nil(t, resolvePrincipal(t, none, "10.1.2.3:5000", identity))
+}
+
+func TestNewGatewayTrustRejectsInvalidProxies(t *testing.T) {
+	_, err := NewGatewayTrust([]string{"gateway.internal"}, "", "")
+	assert.ErrorContains(t, err, `invalid trusted proxy "gateway.internal"`)
+}
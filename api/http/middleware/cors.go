@@ -1,10 +1,17 @@
 package middleware
 
 import (
+	"context"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
 )
 
 // CORS related constants
@@ -13,14 +20,184 @@ const (
 	CORSMaxAge = 12 * time.Hour
 )
 
-// Cors provides a CORS middleware
+// Default CORS policy used for settings missing from the configuration
+var (
+	defaultCORSMethods       = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	defaultCORSHeaders       = []string{"Origin", "Content-Type", "Accept", "Authorization", app_context.RequestIDKey, IdempotencyKeyHeader}
+	defaultCORSExposeHeaders = []string{
+		"Content-Length",
+		app_context.RequestIDKey,
+		RateLimitLimitHeader,
+		RateLimitRemainingHeader,
+		RateLimitResetHeader,
+		RetryAfterHeader,
+		IdempotentReplayedHeader,
+	}
+)
+
+// corsState holds the CORS handler built from the current configuration
+type corsState struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// Cors provides a CORS middleware driven by http_server.cors.
+// The policy is rebuilt when the configuration is reloaded; an invalid policy keeps the previous one.
 func Cors() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           CORSMaxAge,
+	state := &corsState{}
+	state.rebuild(corsConfig(config.Get()))
+	config.Subscribe(func(change config.Change) {
+		if change.Changed("http_server.cors") {
+			state.rebuild(corsConfig(change.New))
+		}
 	})
+
+	return func(c *gin.Context) {
+		(*state.handler.Load())(c)
+	}
+}
+
+// rebuild replaces the handler with one built from conf, keeping the previous handler if conf is invalid
+func (s *corsState) rebuild(conf *config.CORSConfig) {
+	handler, err := newCorsHandler(conf)
+	if err != nil {
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Errorf("Cors.newCorsHandler err, keeping previous policy: %v", err)
+		if s.handler.Load() != nil {
+			return
+		}
+		handler, _ = newCorsHandler(&config.CORSConfig{})
+	}
+	s.handler.Store(&handler)
+}
+
+// corsConfig returns the CORS policy of conf, or an empty one when it is not configured
+func corsConfig(conf *config.Config) *config.CORSConfig {
+	if conf == nil || conf.HTTPServer == nil || conf.HTTPServer.CORS == nil {
+		return &config.CORSConfig{}
+	}
+	return conf.HTTPServer.CORS
+}
+
+// newCorsHandler builds a CORS handler from the configuration, filling in defaults for missing settings.
+// Origins may be exact ("https://app.example.com"), "*" or match subdomains ("https://*.example.com").
+func newCorsHandler(conf *config.CORSConfig) (gin.HandlerFunc, error) {
+	corsConf := cors.Config{
+		AllowMethods:     withDefault(conf.AllowMethods, defaultCORSMethods),
+		AllowHeaders:     withDefault(conf.AllowHeaders, defaultCORSHeaders),
+		ExposeHeaders:    withDefault(conf.ExposeHeaders, defaultCORSExposeHeaders),
+		AllowCredentials: conf.AllowCredentials,
+		MaxAge:           CORSMaxAge,
+	}
+	if conf.MaxAge != "" {
+		corsConf.MaxAge = config.GetDuration(conf.MaxAge)
+	}
+
+	origins := withDefault(conf.AllowOrigins, []string{"*"})
+	matcher, allowAll := newOriginMatcher(origins)
+	if allowAll && conf.AllowCredentials {
+		// Browsers reject credentialed responses for any origin, so this is a misconfiguration
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Warnf("CORS allows any origin, ignoring allow_credentials")
+		corsConf.AllowCredentials = false
+	}
+	if allowAll {
+		corsConf.AllowAllOrigins = true
+	} else {
+		corsConf.AllowOriginFunc = matcher.matches
+	}
+
+	if err := corsConf.Validate(); err != nil {
+		return nil, err
+	}
+	return cors.New(corsConf), nil
+}
+
+// originPattern is an allowed origin, optionally matching any subdomain of host
+type originPattern struct {
+	scheme    string
+	host      string
+	port      string
+	subdomain bool
+}
+
+// originMatcher checks request origins against the allowed patterns
+type originMatcher struct {
+	patterns []originPattern
+}
+
+// newOriginMatcher parses the allowed origins, reporting whether any origin is allowed.
+// Malformed origins are logged and skipped.
+func newOriginMatcher(origins []string) (*originMatcher, bool) {
+	matcher := &originMatcher{}
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			return matcher, true
+		}
+
+		pattern, ok := parseOriginPattern(origin)
+		if !ok {
+			logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Warnf("Ignoring invalid CORS origin %q", origin)
+			continue
+		}
+		matcher.patterns = append(matcher.patterns, pattern)
+	}
+	return matcher, false
+}
+
+// parseOriginPattern parses an origin such as "https://*.example.com:8443"
+func parseOriginPattern(origin string) (originPattern, bool) {
+	scheme, rest, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok || scheme == "" || rest == "" || strings.Contains(rest, "/") {
+		return originPattern{}, false
+	}
+
+	pattern := originPattern{scheme: scheme}
+	if strings.HasPrefix(rest, "*.") {
+		pattern.subdomain = true
+		rest = strings.TrimPrefix(rest, "*.")
+	}
+
+	host, port := rest, ""
+	if idx := strings.LastIndex(rest, ":"); idx != -1 {
+		host, port = rest[:idx], rest[idx+1:]
+	}
+	if host == "" || strings.Contains(host, "*") {
+		return originPattern{}, false
+	}
+
+	pattern.host = host
+	pattern.port = port
+	return pattern, true
+}
+
+// matches reports whether the request origin is allowed
+func (m *originMatcher) matches(origin string) bool {
+	parsed, err := url.Parse(strings.ToLower(origin))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return false
+	}
+	host, port := parsed.Hostname(), parsed.Port()
+
+	for _, pattern := range m.patterns {
+		if pattern.scheme != parsed.Scheme || pattern.port != port {
+			continue
+		}
+		if pattern.subdomain {
+			if strings.HasSuffix(host, "."+pattern.host) {
+				return true
+			}
+			continue
+		}
+		if host == pattern.host {
+			return true
+		}
+	}
+	return false
+}
+
+// withDefault returns values, or fallback when values is empty
+func withDefault(values, fallback []string) []string {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
 }
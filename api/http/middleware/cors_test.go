@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+
+	matcher, allowAll := newOriginMatcher([]string{
+		"http://localhost:3000",
+		"https://*.example.com",
+		"not-an-origin",
+	})
+	require.False(t, allowAll)
+	assert.Len(t, matcher.patterns, 2)
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"https://localhost:3000", false},
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://APP.Example.com", true},
+		{"https://example.com", false},
+		{"https://evilexample.com", false},
+		{"https://example.com.evil.org", false},
+		{"http://app.example.com", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			assert.Equal(t, tt.want, matcher.matches(tt.origin))
+		})
+	}
+
+	_, allowAll = newOriginMatcher([]string{"https://app.example.com", "*"})
+	assert.True(t, allowAll)
+}
+
+func TestCorsHandler(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+
+	serve := func(conf *config.CORSConfig, origin string) *httptest.ResponseRecorder {
+		handler, err := newCorsHandler(conf)
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.Use(handler)
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", origin)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	conf := &config.CORSConfig{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowCredentials: true,
+	}
+	w := serve(conf, "https://app.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = serve(conf, "https://evil.org")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Credentials are never combined with a wildcard origin
+	w = serve(&config.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}, "https://evil.org")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+// reloadConfig replaces the current configuration as a reload of the configuration file does
+func reloadConfig(t *testing.T, mutate func(conf *config.Config)) {
+	t.Helper()
+	conf := &config.Config{
+		App:        &config.AppConfig{},
+		HTTPServer: &config.HttpServerConfig{Addr: ":8080", DefaultPageSize: 10, MaxPageSize: 100},
+		Log:        &config.LogConfig{},
+	}
+	mutate(conf)
+	_, err := config.Update(conf)
+	require.NoError(t, err)
+}
+
+func TestCorsFollowsReload(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+	reloadConfig(t, func(conf *config.Config) {
+		conf.HTTPServer.CORS = &config.CORSConfig{AllowOrigins: []string{"https://app.example.com"}}
+	})
+
+	router := gin.New()
+	router.Use(Cors())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func(origin string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", origin)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("https://app.example.com"))
+	assert.Equal(t, http.StatusForbidden, serve("https://admin.example.com"))
+
+	reloadConfig(t, func(conf *config.Config) {
+		conf.HTTPServer.CORS = &config.CORSConfig{AllowOrigins: []string{"https://admin.example.com"}}
+	})
+	assert.Equal(t, http.StatusForbidden, serve("https://app.example.com"))
+	assert.Equal(t, http.StatusOK, serve("https://admin.example.com"))
+}
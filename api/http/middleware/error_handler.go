@@ -34,6 +34,13 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 				})
 				return
 
+			case errors.IsForbiddenError(err):
+				c.JSON(http.StatusForbidden, gin.H{
+					"code":    error_code.ForbiddenErrorCode,
+					"message": err.Error(),
+				})
+				return
+
 			case errors.IsPersistenceError(err):
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"code":    error_code.ServerErrorCode,
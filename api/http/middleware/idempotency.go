@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/pkg/idempotency"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = 30 * time.Second
+)
+
+// idempotentMethods are the methods an Idempotency-Key is honoured for
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// idempotencyWriter captures the response so it can be stored for replays
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency returns a middleware replaying the stored response for repeated Idempotency-Key requests.
+// A key reused with another request, or while the first request is still running, is rejected with 409.
+// Requests are processed normally if the store is unavailable.
+func Idempotency(store idempotency.Store, ttl, lockTTL time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	if lockTTL <= 0 {
+		lockTTL = defaultIdempotencyLockTTL
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		response := handle.NewResponse(c)
+		if len(key) > maxIdempotencyKeyLength {
+			response.ToErrorResponse(error_code.InvalidParams.WithDetails("Idempotency-Key is too long"))
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				response.ToErrorResponse(bodyReadError(err))
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		storeKey := idempotencyScope(c) + key
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, acquired, err := store.Acquire(c.Request.Context(), storeKey, fingerprint, lockTTL)
+		if err != nil {
+			logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("Idempotency.Acquire err, processing without idempotency: %v", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.ToErrorResponse(error_code.IdempotencyKeyReused)
+			case record.State != idempotency.StateCompleted:
+				response.ToErrorResponse(error_code.IdempotencyKeyInFlight)
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		// Store the outcome even if the client has gone away, so its retry is replayed
+		ctx := context.WithoutCancel(c.Request.Context())
+		release := func() {
+			if err := store.Release(ctx, storeKey); err != nil {
+				logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("Idempotency.Release err: %v", err)
+			}
+		}
+		// A panicking handler must not leave the key in flight until the lock expires
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			// Server errors are not final; let the client retry with the same key
+			release()
+			return
+		}
+		if c.Request.Context().Err() != nil && !writer.Written() {
+			// The deadline fired before the handler answered, Timeout replaces the response
+			release()
+			return
+		}
+
+		record.StatusCode = status
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.Body = writer.body.Bytes()
+		if err := store.Complete(ctx, storeKey, record, ttl); err != nil {
+			logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("Idempotency.Complete err: %v", err)
+		}
+	}
+}
+
+// idempotencyScope keeps keys of different authenticated callers apart
+func idempotencyScope(c *gin.Context) string {
+	if val, exists := c.Get(app_context.ContextKey); exists {
+		if appCtx, ok := val.(*app_context.AppContext); ok && appCtx.Principal != nil {
+			return appCtx.Principal.Subject + ":"
+		}
+	}
+	return ""
+}
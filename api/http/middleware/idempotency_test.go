@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/idempotency"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// newIdempotentRouter serves handlers counting their calls behind the Idempotency middleware.
+// /flaky fails with 500 and /panic panics on their first call.
+func newIdempotentRouter(t *testing.T) (*gin.Engine, *idempotency.RedisStore, map[string]int) {
+	t.Helper()
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := idempotency.NewRedisStore(client)
+
+	calls := make(map[string]int)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(Idempotency(store, time.Hour, time.Minute))
+	router.POST("/ok", func(c *gin.Context) {
+		calls["ok"]++
+		c.JSON(http.StatusCreated, gin.H{"call": calls["ok"]})
+	})
+	router.POST("/flaky", func(c *gin.Context) {
+		if calls["flaky"]++; calls["flaky"] == 1 {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	router.POST("/panic", func(c *gin.Context) {
+		if calls["panic"]++; calls["panic"] == 1 {
+			panic("handler failed")
+		}
+		c.Status(http.StatusCreated)
+	})
+	return router, store, calls
+}
+
+func postIdempotent(router *gin.Engine, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplays(t *testing.T) {
+	router, _, calls := newIdempotentRouter(t)
+
+	first := postIdempotent(router, "/ok", "key-1", `{"a":1}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	replay := postIdempotent(router, "/ok", "key-1", `{"a":1}`)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, 1, calls["ok"])
+
+	// The key cannot be reused with another body
+	reused := postIdempotent(router, "/ok", "key-1", `{"a":2}`)
+	assert.Equal(t, http.StatusConflict, reused.Code)
+	assert.Contains(t, reused.Body.String(), `"code":10004`)
+	assert.Equal(t, 1, calls["ok"])
+}
+
+func TestIdempotencyRejectsInFlight(t *testing.T) {
+	router, store, calls := newIdempotentRouter(t)
+
+	// Another instance is processing the same request
+	fingerprint := idempotency.Fingerprint(http.MethodPost, "/ok", []byte(`{"a":1}`))
+	_, acquired, err := store.Acquire(context.Background(), "key-1", fingerprint, time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	w := postIdempotent(router, "/ok", "key-1", `{"a":1}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":10005`)
+	assert.Zero(t, calls["ok"])
+}
+
+func TestIdempotencyReleasesFailedRequests(t *testing.T) {
+	router, _, calls := newIdempotentRouter(t)
+
+	// Server errors are retried rather than replayed
+	assert.Equal(t, http.StatusInternalServerError, postIdempotent(router, "/flaky", "key-1", "").Code)
+	assert.Equal(t, http.StatusCreated, postIdempotent(router, "/flaky", "key-1", "").Code)
+	assert.Equal(t, 2, calls["flaky"])
+
+	// So are panics, which must not leave the key in flight
+	assert.Equal(t, http.StatusInternalServerError, postIdempotent(router, "/panic", "key-2", "").Code)
+	assert.Equal(t, http.StatusCreated, postIdempotent(router, "/panic", "key-2", "").Code)
+	assert.Equal(t, 2, calls["panic"])
+}
+
+func TestIdempotencyRejectsBodyOverLimit(t *testing.T) {
+	router, _, calls := newIdempotentRouter(t)
+	limited := gin.New()
+	limited.Use(BodyLimit(4))
+	limited.Any("/*path", gin.WrapH(router))
+
+	req := httptest.NewRequest(http.MethodPost, "/ok", strings.NewReader(`{"a":1}`))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	limited.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Zero(t, calls["ok"])
+}
+
+func TestIdempotencyReleasesTimedOutRequests(t *testing.T) {
+	router, _, calls := newIdempotentRouter(t)
+	timed := gin.New()
+	timed.Use(Timeout(&config.HttpServerConfig{HandlerTimeout: "10ms"}))
+	timed.Any("/*path", gin.WrapH(router))
+	router.POST("/slow", func(c *gin.Context) {
+		if calls["slow"]++; calls["slow"] == 1 {
+			// Gives up on the deadline without answering
+			<-c.Request.Context().Done()
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, postIdempotent(timed, "/slow", "key-1", "").Code)
+	// The empty response was not stored in place of the timeout, the retry is processed
+	retry := postIdempotent(timed, "/slow", "key-1", "")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls["slow"])
+}
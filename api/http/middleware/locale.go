@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
+)
+
+// Locale negotiates the locale of each request from the lang query parameter or cookie and the
+// Accept-Language header, falling back to the configured default locale. Handlers, services and
+// error responses read it with i18n.RequestLocale or i18n.FromContext.
+func Locale(conf *config.I18nConfig) gin.HandlerFunc {
+	fallback := i18n.DefaultLocale
+	if conf != nil && conf.DefaultLocale != "" {
+		fallback = conf.DefaultLocale
+	}
+
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.Request, fallback)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+
+		// Caches must keep one response per language
+		c.Writer.Header().Set("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Writer.Header().Add("Vary", "Cookie")
+
+		c.Next()
+	}
+}
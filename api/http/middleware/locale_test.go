@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
+)
+
+func TestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Locale(&config.I18nConfig{DefaultLocale: "vi"}))
+	router.GET("/missing", func(c *gin.Context) {
+		handle.NewResponse(c).ToErrorResponse(error_code.NotFound)
+	})
+	router.GET("/locale", func(c *gin.Context) {
+		locale, _ := i18n.FromContext(c.Request.Context())
+		c.String(http.StatusOK, locale)
+	})
+
+	serve := func(target, acceptLanguage string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/missing", "vi-VN,vi;q=0.9,en;q=0.8")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "vi", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+	assert.Contains(t, w.Header().Values("Vary"), "Cookie")
+	var body handle.StandardResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "không tìm thấy bản ghi", body.Meta.Message)
+
+	assert.Equal(t, "en", serve("/locale", "en-GB").Body.String())
+	assert.Equal(t, "en", serve("/locale?lang=en", "vi").Body.String())
+	// Unsupported languages fall back to the configured default
+	assert.Equal(t, "vi", serve("/locale", "fr").Body.String())
+}
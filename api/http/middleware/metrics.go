@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
+)
+
+// UnknownRoute is the handler label of requests matching no route, keeping label cardinality bounded
+const UnknownRoute = "unknown"
+
+// Metrics is a middleware that records the duration and status of each request, labelled with the route template
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !metrics.Initialized() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		handlerName := c.FullPath()
+		if handlerName == "" {
+			handlerName = UnknownRoute
+		}
+		metrics.ObserveHTTPRequest(handlerName, c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	require.NoError(t, metrics.Init())
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	served := testutil.ToFloat64(metrics.RequestTotal.WithLabelValues("/items/:id", http.MethodGet, "404"))
+	unknown := testutil.ToFloat64(metrics.RequestTotal.WithLabelValues(UnknownRoute, http.MethodGet, "404"))
+	clientErrors := testutil.ToFloat64(metrics.ErrorTotal.WithLabelValues("client_error", "/items/:id"))
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are labelled with the route template, not the raw path
+	assert.Equal(t, served+2, testutil.ToFloat64(metrics.RequestTotal.WithLabelValues("/items/:id", http.MethodGet, "404")))
+	assert.Equal(t, unknown+1, testutil.ToFloat64(metrics.RequestTotal.WithLabelValues(UnknownRoute, http.MethodGet, "404")))
+	assert.Equal(t, clientErrors+2, testutil.ToFloat64(metrics.ErrorTotal.WithLabelValues("client_error", "/items/:id")))
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/ratelimit"
+)
+
+// Rate limit client identification strategies
+const (
+	KeyByIP      = "ip"
+	KeyByAPIKey  = "api_key"
+	KeyBySubject = "subject"
+)
+
+// Rate limit response headers
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// APIKeyHeader is the header carrying a client API key
+const APIKeyHeader = "X-Api-Key"
+
+// rateLimitPolicy is a rule together with how clients are identified for it
+type rateLimitPolicy struct {
+	scope string
+	keyBy string
+	rule  ratelimit.Rule
+}
+
+// rateLimitPolicies are the global policy and the per-route overrides keyed by method and path
+type rateLimitPolicies struct {
+	defaultPolicy *rateLimitPolicy
+	routes        map[string]*rateLimitPolicy
+}
+
+// RateLimit returns a middleware limiting requests per client using the global rule and per-route overrides.
+// Invalid rules are logged and skipped; requests are let through if the limiter fails.
+// The rules follow configuration reloads, disabling rate_limit lets every request through.
+func RateLimit(limiter ratelimit.Limiter, cfg *config.RateLimitConfig) gin.HandlerFunc {
+	var policies atomic.Pointer[rateLimitPolicies]
+	policies.Store(buildRateLimitPolicies(cfg))
+	config.Subscribe(func(change config.Change) {
+		if change.Changed("rate_limit") {
+			policies.Store(buildRateLimitPolicies(change.New.RateLimit))
+			logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Infof("Rate limit rules reloaded")
+		}
+	})
+
+	return func(c *gin.Context) {
+		current := policies.Load()
+		policy, ok := current.routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			policy = current.defaultPolicy
+		}
+		if policy == nil {
+			c.Next()
+			return
+		}
+
+		key := policy.scope + ":" + rateLimitIdentity(c, policy.keyBy)
+		result, err := limiter.Allow(c.Request.Context(), key, policy.rule)
+		if err != nil {
+			logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("RateLimit.Allow err, letting request through: %v", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		header.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		header.Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			header.Set(RetryAfterHeader, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			handle.NewResponse(c).ToErrorResponse(error_code.TooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// buildRateLimitPolicies creates the policies of cfg, none when rate limiting is disabled
+func buildRateLimitPolicies(cfg *config.RateLimitConfig) *rateLimitPolicies {
+	policies := &rateLimitPolicies{}
+	if cfg == nil || !cfg.Enabled {
+		return policies
+	}
+	policies.defaultPolicy = buildRateLimitPolicy("global", cfg.Algorithm, cfg.KeyBy, cfg.Limit, cfg.Window, cfg.Burst)
+
+	policies.routes = make(map[string]*rateLimitPolicy, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		algorithm := route.Algorithm
+		if algorithm == "" {
+			algorithm = cfg.Algorithm
+		}
+		keyBy := route.KeyBy
+		if keyBy == "" {
+			keyBy = cfg.KeyBy
+		}
+
+		routeKey := strings.ToUpper(route.Method) + " " + route.Path
+		if policy := buildRateLimitPolicy(routeKey, algorithm, keyBy, route.Limit, route.Window, route.Burst); policy != nil {
+			policies.routes[routeKey] = policy
+		}
+	}
+	return policies
+}
+
+// buildRateLimitPolicy creates a policy from configuration values, returning nil if they are invalid
+func buildRateLimitPolicy(scope, algorithm, keyBy string, limit int, window string, burst int) *rateLimitPolicy {
+	if algorithm == "" {
+		algorithm = ratelimit.AlgorithmSlidingWindow
+	}
+	if keyBy == "" {
+		keyBy = KeyByIP
+	}
+
+	rule := ratelimit.Rule{
+		Algorithm: algorithm,
+		Limit:     limit,
+		Window:    config.GetDuration(window),
+		Burst:     burst,
+	}
+	if err := rule.Validate(); err != nil {
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Errorf("Invalid rate limit rule for %s, skipping: %v", scope, err)
+		return nil
+	}
+
+	return &rateLimitPolicy{scope: scope, keyBy: keyBy, rule: rule}
+}
+
+// rateLimitIdentity identifies the client, falling back to the client IP when the preferred identity is absent.
+// The client IP is only taken from X-Forwarded-For when the engine trusts the peer as a proxy, see
+// gin.Engine.SetTrustedProxies, so clients cannot escape their limit by forging the header.
+func rateLimitIdentity(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case KeyByAPIKey:
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			// Hash the key so credentials never end up in the store
+			sum := sha256.Sum256([]byte(apiKey))
+			return "key:" + hex.EncodeToString(sum[:16])
+		}
+	case KeyBySubject:
+		if val, exists := c.Get(app_context.ContextKey); exists {
+			if appCtx, ok := val.(*app_context.AppContext); ok && appCtx.Principal != nil {
+				return "sub:" + appCtx.Principal.Subject
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds for header values
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/ratelimit"
+)
+
+func TestRateLimitFollowsReload(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RateLimit(ratelimit.NewMemoryLimiter(), &config.RateLimitConfig{Enabled: true, Limit: 1, Window: "1m"}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve())
+
+	// Raising the limit applies to the next request
+	reloadConfig(t, func(conf *config.Config) {
+		conf.RateLimit = &config.RateLimitConfig{Enabled: true, Limit: 3, Window: "1m"}
+	})
+	assert.Equal(t, http.StatusOK, serve())
+
+	// Disabling rate limiting lets every request through
+	reloadConfig(t, func(conf *config.Config) {
+		conf.RateLimit = &config.RateLimitConfig{Enabled: false}
+	})
+	for range 5 {
+		assert.Equal(t, http.StatusOK, serve())
+	}
+}
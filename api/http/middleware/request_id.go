@@ -2,12 +2,13 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
 	"github.com/ntdat104/go-clean-architecture/pkg/uuid"
 )
 
 const (
 	// RequestIDHeader is the header key for request ID
-	RequestIDHeader = "X-Request-ID"
+	RequestIDHeader = app_context.RequestIDKey
 )
 
 // RequestID is a middleware that injects a request ID into the context of each request
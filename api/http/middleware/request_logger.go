@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"bytes"
+	"cmp"
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -11,20 +13,40 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/redact"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
 )
 
+// credentialHeaders are the headers logged under their own field, passed through the redactor
+var credentialHeaders = []struct {
+	name  string
+	field string
+}{
+	{"X-Api-Key", "x_api_key"},
+	{"X-Api-Secret", "x_api_secret"},
+	{"Authorization", "authorization"},
+	{"Signature", "signature"},
+}
+
 // ResponseWriter is a wrapper for gin.ResponseWriter that captures the
-// response status code and size
+// response status code and the start of the body
 type ResponseWriter struct {
 	gin.ResponseWriter
+	// body holds the first bodyLimit bytes written, nil when the body is not logged
 	body       *bytes.Buffer
+	bodyLimit  int
 	statusCode int
 }
 
-// Write captures the response body and writes it to the underlying writer
+// Write captures the loggable start of the response body and writes it to the underlying writer
 func (rw *ResponseWriter) Write(b []byte) (int, error) {
-	rw.body.Write(b)
+	if rw.body != nil {
+		if room := rw.bodyLimit - rw.body.Len(); room > 0 {
+			rw.body.Write(b[:min(room, len(b))])
+		}
+	}
 	return rw.ResponseWriter.Write(b)
 }
 
@@ -45,10 +67,14 @@ type LoggingConfig struct {
 	LogRequestBody bool
 	// Whether to log response body (disabled by default for privacy and size reasons)
 	LogResponseBody bool
-	// Maximum size of request/response body to log
+	// Maximum size of request/response body to log after redaction, zero keeps the redactor limit.
+	// Only this many bytes of the request and response bodies are kept for logging, so larger JSON
+	// bodies, which cannot be redacted once cut, are not logged.
 	MaxBodyLogSize int
 	// Skip logging for specified paths
 	SkipPaths []string
+	// Redactor masks secrets in headers and bodies, defaults to the log.redaction configuration
+	Redactor *redact.Redactor
 }
 
 // DefaultLoggingConfig returns the default logging configuration
@@ -56,8 +82,8 @@ func DefaultLoggingConfig() *LoggingConfig {
 	return &LoggingConfig{
 		LogRequestBody:  true,
 		LogResponseBody: true,
-		MaxBodyLogSize:  1024, // 1 KB
 		SkipPaths:       []string{"/ping", "/health"},
+		Redactor:        redactorFromGlobal(),
 	}
 }
 
@@ -71,6 +97,10 @@ func RequestLoggerWithConfig(config *LoggingConfig) gin.HandlerFunc {
 	if config == nil {
 		config = DefaultLoggingConfig()
 	}
+	redactor := config.Redactor
+	if redactor == nil {
+		redactor = redactorFromGlobal()
+	}
 
 	return func(c *gin.Context) {
 		// Skip logging for certain paths
@@ -90,27 +120,34 @@ func RequestLoggerWithConfig(config *LoggingConfig) gin.HandlerFunc {
 		// Start timer
 		start := time.Now()
 
-		// Read request body if enabled
-		var requestBody []byte
-		if config.LogRequestBody && c.Request.Body != nil {
-			var err error
-			requestBody, err = io.ReadAll(c.Request.Body)
-			if err != nil {
-				logger.Logger.Error("Failed to read request body", zap.Error(err))
-			}
-
-			// Restore request body
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
-		}
+		// Bodies are only logged for sampled requests
+		logBodies := (config.LogRequestBody || config.LogResponseBody) && redactor.SampleBody()
 
 		// Create a response writer wrapper to capture the response
 		responseWriter := &ResponseWriter{
 			ResponseWriter: c.Writer,
-			body:           &bytes.Buffer{},
 			statusCode:     http.StatusOK, // Default status is 200
 		}
+		if config.LogResponseBody && logBodies {
+			// Like the request body, only the part that can be logged is kept
+			responseWriter.body = &bytes.Buffer{}
+			responseWriter.bodyLimit = cmp.Or(config.MaxBodyLogSize, redactor.MaxBodySize())
+		}
 		c.Writer = responseWriter
 
+		// Read the loggable start of the request body if enabled, the handler reads the rest
+		var requestBody []byte
+		if config.LogRequestBody && logBodies && c.Request.Body != nil && redactor.LogsContentType(c.GetHeader("Content-Type")) {
+			var err error
+			requestBody, err = peekBody(c.Request, cmp.Or(config.MaxBodyLogSize, redactor.MaxBodySize()))
+			if err != nil {
+				// The handler would get a cut-off body
+				logger.ForComponent(c, logger.ComponentHTTP).Warn("Failed to read request body", zap.Error(err))
+				handle.NewResponse(c).ToErrorResponse(bodyReadError(err))
+				c.Abort()
+			}
+		}
+
 		// Process request
 		c.Next()
 
@@ -123,14 +160,16 @@ func RequestLoggerWithConfig(config *LoggingConfig) gin.HandlerFunc {
 		// Check if request path is an API path
 		isAPIPath := len(c.Errors) == 0 && c.Writer.Status() < 500
 
-		// Determine log level based on status code
+		// Determine log level based on status code. The fields of the request are added below,
+		// so the global logger is used rather than the one of the request context.
+		log := logger.ForComponent(context.Background(), logger.ComponentHTTP)
 		var logMethod func(string, ...zap.Field)
 		if c.Writer.Status() >= 500 {
-			logMethod = logger.Logger.Error
+			logMethod = log.Error
 		} else if c.Writer.Status() >= 400 {
-			logMethod = logger.Logger.Warn
+			logMethod = log.Warn
 		} else {
-			logMethod = logger.Logger.Info
+			logMethod = log.Info
 		}
 
 		// Create log fields
@@ -144,49 +183,40 @@ func RequestLoggerWithConfig(config *LoggingConfig) gin.HandlerFunc {
 			zap.Int64("latency_ms", duration.Milliseconds()),
 			zap.String("ip", c.ClientIP()),
 			zap.String("user-agent", c.Request.UserAgent()),
-			zap.String("app_name", glbConfig.GlobalConfig.App.Name),
-			zap.String("app_version", glbConfig.GlobalConfig.App.Version),
+			zap.String("app_name", glbConfig.Get().App.Name),
+			zap.String("app_version", glbConfig.Get().App.Version),
 			zap.String("start_time", formattedStart),
 			zap.String("end_time", formattedEnd),
 		}
 
-		// Add x_api_key header if present
-		if apiKey := c.GetHeader("X-Api-Key"); apiKey != "" {
-			fields = append(fields, zap.String("x_api_key", apiKey))
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
 		}
 
-		// Add x_api_secret header if present
-		if apiSecret := c.GetHeader("X-Api-Secret"); apiSecret != "" {
-			fields = append(fields, zap.String("x_api_secret", apiSecret))
-		}
-
-		// Add authorization header if present
-		if auth := c.GetHeader("Authorization"); auth != "" {
-			fields = append(fields, zap.String("authorization", auth))
+		// Add credential headers if present, masked unless explicitly allowed
+		for _, header := range credentialHeaders {
+			if value := c.GetHeader(header.name); value != "" {
+				fields = append(fields, zap.String(header.field, redactor.Header(header.name, value)))
+			}
 		}
 
-		// Add signature header if present
-		if signature := c.GetHeader("Signature"); signature != "" {
-			fields = append(fields, zap.String("signature", signature))
+		// Add allow listed headers
+		if headers := redactor.Headers(c.Request.Header); len(headers) > 0 {
+			fields = append(fields, zap.Any("headers", headers))
 		}
 
 		// Add request body if enabled and present
-		if config.LogRequestBody && len(requestBody) > 0 {
-			// Limit the size of the logged body
-			if len(requestBody) > config.MaxBodyLogSize {
-				requestBody = requestBody[:config.MaxBodyLogSize]
+		if config.LogRequestBody && logBodies {
+			if body, ok := redactor.Body(c.GetHeader("Content-Type"), requestBody); ok {
+				fields = append(fields, zap.String("request_body", limitBody(body, config.MaxBodyLogSize)))
 			}
-			fields = append(fields, zap.ByteString("request_body", requestBody))
 		}
 
 		// Add response body if enabled
-		if config.LogResponseBody && responseWriter.body.Len() > 0 {
-			responseBody := responseWriter.body.Bytes()
-			// Limit the size of the logged body
-			if len(responseBody) > config.MaxBodyLogSize {
-				responseBody = responseBody[:config.MaxBodyLogSize]
+		if config.LogResponseBody && logBodies {
+			if body, ok := redactor.Body(responseWriter.Header().Get("Content-Type"), responseWriter.body.Bytes()); ok {
+				fields = append(fields, zap.String("response_body", limitBody(body, config.MaxBodyLogSize)))
 			}
-			fields = append(fields, zap.ByteString("response_body", responseBody))
 		}
 
 		// Add error if present
@@ -202,3 +232,30 @@ func RequestLoggerWithConfig(config *LoggingConfig) gin.HandlerFunc {
 		logMethod(message, fields...)
 	}
 }
+
+// peekBody reads up to limit bytes of the request body and puts them back in front of the unread rest
+func peekBody(req *http.Request, limit int) ([]byte, error) {
+	body := req.Body
+	prefix, err := io.ReadAll(io.LimitReader(body, int64(limit)))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), body), body}
+	return prefix, err
+}
+
+// redactorFromGlobal creates a redactor from the log.redaction configuration
+func redactorFromGlobal() *redact.Redactor {
+	if glbConfig.GlobalConfig == nil || glbConfig.GlobalConfig.Log == nil {
+		return redact.New(nil)
+	}
+	return redact.New(glbConfig.GlobalConfig.Log.Redaction)
+}
+
+// limitBody truncates an already redacted body to maxSize bytes, zero means no limit
+func limitBody(body string, maxSize int) string {
+	if maxSize <= 0 || len(body) <= maxSize {
+		return body
+	}
+	return body[:maxSize]
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/redact"
+)
+
+// newLoggedRouter echoes request bodies behind BodyLimit and RequestLogger, recording the logs
+func newLoggedRouter(t *testing.T, maxBytes int64, maxBodyLogSize int) (*gin.Engine, *observer.ObservedLogs) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if config.GlobalConfig == nil {
+		config.GlobalConfig = &config.Config{App: &config.AppConfig{Name: "test"}}
+		t.Cleanup(func() { config.GlobalConfig = nil })
+	}
+	core, logs := observer.New(zap.DebugLevel)
+	logger.Logger = zap.New(core)
+
+	router := gin.New()
+	router.Use(BodyLimit(maxBytes))
+	router.Use(RequestLoggerWithConfig(&LoggingConfig{
+		LogRequestBody: true,
+		MaxBodyLogSize: maxBodyLogSize,
+		Redactor:       redact.New(nil),
+	}))
+	router.POST("/", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	})
+	return router, logs
+}
+
+func post(router *gin.Engine, contentType, body string, chunked bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if chunked {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequestLoggerRestoresBody(t *testing.T) {
+	router, logs := newLoggedRouter(t, 0, 8)
+
+	body := "plain text longer than the logged part"
+	w := post(router, "text/plain", body, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String())
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "plain te", logs.All()[0].ContextMap()["request_body"])
+
+	// Bodies of content types which are not logged are not read
+	w = post(router, "application/octet-stream", body, false)
+	assert.Equal(t, body, w.Body.String())
+	assert.NotContains(t, logs.All()[1].ContextMap(), "request_body")
+}
+
+func TestRequestLoggerRejectsBodyOverLimit(t *testing.T) {
+	router, logs := newLoggedRouter(t, 8, 64)
+
+	w := post(router, "application/json", `{"a":"too long"}`, true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, int64(http.StatusRequestEntityTooLarge), logs.All()[1].ContextMap()["status"])
+}
+
+func TestRequestLoggerCapturesResponsePrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.DebugLevel)
+	logger.Logger = zap.New(core)
+
+	router := gin.New()
+	router.Use(RequestLoggerWithConfig(&LoggingConfig{
+		LogResponseBody: true,
+		MaxBodyLogSize:  8,
+		Redactor:        redact.New(nil),
+	}))
+	var captured *ResponseWriter
+	router.GET("/", func(c *gin.Context) {
+		captured = c.Writer.(*ResponseWriter)
+		c.String(http.StatusOK, "plain text longer than the logged part")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "plain text longer than the logged part", w.Body.String())
+	// Only the logged part of the response is kept
+	assert.Equal(t, "plain te", captured.body.String())
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "plain te", logs.All()[0].ContextMap()["response_body"])
+}
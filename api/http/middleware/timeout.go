@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// timeoutWriter buffers the response so it can be replaced if the deadline fires. The whole body is held
+// until the handler returns: this costs no more than the handlers rendering their JSON responses in
+// memory do, but rules out streaming responses, which are sent at once when the handler is done.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.written
+}
+
+// Timeout returns a middleware giving each request a deadline, propagated to services and repositories
+// through the request context. Routes listed in RouteTimeouts use their own deadline.
+// When the deadline fires before a successful response, the handler's response is replaced with a 504,
+// or a 503 if the request was cancelled. Handlers must honour the context for the deadline to take effect.
+func Timeout(cfg *config.HttpServerConfig) gin.HandlerFunc {
+	defaultTimeout := config.GetDuration(cfg.HandlerTimeout)
+	routeTimeouts := make(map[string]time.Duration, len(cfg.RouteTimeouts))
+	for _, route := range cfg.RouteTimeouts {
+		timeout := config.GetDuration(route.Timeout)
+		if timeout <= 0 {
+			logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Errorf("Invalid timeout %q for %s %s, skipping", route.Timeout, route.Method, route.Path)
+			continue
+		}
+		routeTimeouts[strings.ToUpper(route.Method)+" "+route.Path] = timeout
+	}
+
+	return func(c *gin.Context) {
+		timeout, ok := routeTimeouts[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		writer := &timeoutWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = original
+
+		if err := ctx.Err(); err != nil && (!writer.written || writer.status >= http.StatusInternalServerError) {
+			// Drop whatever the handler prepared and answer with the timeout envelope
+			header := original.Header()
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+
+			if errors.Is(err, context.DeadlineExceeded) {
+				logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("Request %s %s exceeded its %s deadline", c.Request.Method, c.Request.URL.Path, timeout)
+				handle.NewResponse(c).ToErrorResponse(error_code.RequestTimeout)
+			} else {
+				handle.NewResponse(c).ToErrorResponse(error_code.ServiceUnavailable)
+			}
+			return
+		}
+
+		original.WriteHeader(writer.status)
+		if writer.written {
+			original.WriteHeaderNow()
+		}
+		if writer.body.Len() > 0 {
+			if _, err := original.Write(writer.body.Bytes()); err != nil {
+				logger.SugarForComponent(c, logger.ComponentHTTP).Warnf("Timeout.Write err: %v", err)
+			}
+		}
+	}
+}
+
+// BodyLimit returns a middleware rejecting request bodies larger than maxBytes with 413.
+// Bodies without a declared length are cut off once the limit is reached.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			handle.NewResponse(c).ToErrorResponse(error_code.RequestEntityTooLarge)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// bodyReadError returns the API error answering a request whose body could not be read:
+// RequestEntityTooLarge when BodyLimit cut it off, InvalidParams otherwise
+func bodyReadError(err error) *error_code.Error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return error_code.RequestEntityTooLarge
+	}
+	return error_code.InvalidParams.WithDetails("failed to read request body")
+}
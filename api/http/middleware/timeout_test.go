@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/api/http/validator"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTimeoutRouter(cfg *config.HttpServerConfig) *gin.Engine {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(Timeout(cfg))
+	router.GET("/fast", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Done():
+			// Repositories fail with the context error, handlers answer 500
+			c.JSON(http.StatusInternalServerError, gin.H{"error": c.Err().Error()})
+		case <-time.After(time.Second):
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		}
+	})
+	return router
+}
+
+func TestTimeout(t *testing.T) {
+	router := newTimeoutRouter(&config.HttpServerConfig{
+		HandlerTimeout: "20ms",
+		RouteTimeouts: []config.RouteTimeoutConfig{
+			{Method: "get", Path: "/slow", Timeout: "10ms"},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":10006`)
+	assert.NotContains(t, w.Body.String(), "deadline exceeded")
+}
+
+func TestTimeoutDisabled(t *testing.T) {
+	router := newTimeoutRouter(&config.HttpServerConfig{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(BodyLimit(8))
+	router.POST("/", func(c *gin.Context) {
+		var body map[string]any
+		if valid, errs := validator.BindAndValid(c, &body, c.ShouldBindJSON); !valid {
+			handle.NewResponse(c).ToErrorResponse(errs.ToError())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"too long"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+
+	// Bodies without a declared length are cut off at the limit
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"too long"}`))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, error_code.RequestEntityTooLarge.StatusCode())
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+)
+
+// Module is a feature of the HTTP API. Provide registers its repositories and services in the container,
+// then RegisterRoutes resolves what its handlers need. Tests can swap any dependency with container.Override.
+type Module interface {
+	Name() string
+	Provide(c *container.Container)
+	RegisterRoutes(router *gin.Engine, c *container.Container) error
+}
+
+// DefaultModules returns the modules served by the application
+func DefaultModules() []Module {
+	return []Module{
+		ExampleModule{},
+		UserModule{},
+		SystemModule{},
+		AdminModule{},
+	}
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/openapi"
+)
+
+// Component schemas of the response envelope, problem details and error codes
+const (
+	standardResponseSchema = "handle.StandardResponse"
+	problemSchema          = "handle.Problem"
+	errorCodeSchema        = "error_code.ErrorCode"
+)
+
+// RouteDoc documents a route in the OpenAPI document. Request is the type the handler binds: its uri
+// fields are path parameters, its form fields query parameters and its other fields the JSON body.
+// Response is the data of the StandardResponse envelope, none for ToSuccess. Errors are the error codes
+// the route returns besides InvalidParams for a Request and ServerError.
+type RouteDoc struct {
+	Method   string
+	Path     string
+	Summary  string
+	Request  any
+	Response any
+	Errors   []*error_code.Error
+}
+
+// DocumentedModule is a Module describing its routes in the OpenAPI document.
+// The routes without a RouteDoc are documented with their path parameters only.
+type DocumentedModule interface {
+	Module
+	RouteDocs() []RouteDoc
+}
+
+// permissionErrors are returned by the routes behind requirePermission when authorization is enabled
+var permissionErrors = []*error_code.Error{error_code.UnauthorizedAuthNotExist, error_code.Forbidden}
+
+// registerOpenAPI serves the OpenAPI document of the routes of router and its Swagger UI,
+// with the embedded swagger-ui-dist build unless openapi.ui_assets_url overrides it
+func registerOpenAPI(router *gin.Engine, conf *config.OpenAPIConfig, app *config.AppConfig, modules []Module) error {
+	specPath := cmp.Or(conf.Path, openapi.DefaultPath)
+	uiPath := cmp.Or(conf.UIPath, openapi.DefaultUIPath)
+
+	info := openapi.Info{Title: "API", Version: "unknown"}
+	if app != nil {
+		info.Title = cmp.Or(app.Name, info.Title)
+		info.Version = cmp.Or(app.Version, info.Version)
+	}
+	skip := []string{specPath, uiPath}
+	// The embedded swagger-ui-dist build, unless the page is pointed to another copy
+	assetsURL := conf.UIAssetsURL
+	if assetsURL == "" {
+		assetsURL = path.Join(uiPath, openapi.UIAssetsPath)
+		if !openapi.VendoredAssets() {
+			logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Warnf(
+				"Swagger UI assets are not vendored, run go generate ./pkg/openapi or set openapi.ui_assets_url")
+		}
+		router.GET(assetsURL+"/*file", gin.WrapH(http.StripPrefix(assetsURL, openapi.AssetsHandler())))
+		skip = append(skip, assetsURL+"/*file")
+	}
+	ui, err := openapi.UIHandler(info.Title, specPath, assetsURL)
+	if err != nil {
+		return err
+	}
+
+	// The routes of the modules are registered after these, so the document is built on first request
+	document := sync.OnceValue(func() *openapi.Document {
+		return NewOpenAPI(router, info, modules, skip...)
+	})
+	router.GET(specPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, document())
+	})
+	router.GET(uiPath, gin.WrapH(ui))
+	return nil
+}
+
+// NewOpenAPI documents the routes registered on router with the RouteDocs of the modules.
+// Responses are described in the StandardResponse envelope and, for errors, as problem details too.
+// The paths in skip, such as those serving the document, are left out.
+func NewOpenAPI(router *gin.Engine, info openapi.Info, modules []Module, skip ...string) *openapi.Document {
+	if info.Description == "" {
+		info.Description = "Responses are wrapped in the " + standardResponseSchema + " envelope whose meta.code is an " +
+			errorCodeSchema + ". Errors are rendered as RFC 7807 problem details when requested with Accept: " +
+			handle.ProblemContentType + " or when http_server.error_format is problem."
+	}
+
+	builder := openapi.NewBuilder(info)
+	builder.RuleDescription = ruleDescription
+	describeEnvelope(builder)
+
+	type documented struct {
+		module string
+		doc    RouteDoc
+	}
+	docs := make(map[string]documented)
+	for _, module := range modules {
+		if documentedModule, ok := module.(DocumentedModule); ok {
+			for _, doc := range documentedModule.RouteDocs() {
+				docs[doc.Method+" "+doc.Path] = documented{module.Name(), doc}
+			}
+		}
+	}
+
+	for _, route := range router.Routes() {
+		if slices.Contains(skip, route.Path) {
+			continue
+		}
+		entry, ok := docs[route.Method+" "+route.Path]
+		if !ok {
+			builder.AddOperation(route.Method, route.Path, &openapi.Operation{
+				OperationID: operationID(route.Handler),
+				Responses:   map[string]*openapi.Response{strconv.Itoa(http.StatusOK): {Description: http.StatusText(http.StatusOK)}},
+			})
+			continue
+		}
+		builder.AddTag(openapi.Tag{Name: entry.module})
+		builder.AddOperation(route.Method, route.Path, describeRoute(builder, entry.module, route.Handler, entry.doc))
+	}
+	return builder.Document()
+}
+
+// describeRoute describes the operation of a documented route
+func describeRoute(builder *openapi.Builder, module, handler string, doc RouteDoc) *openapi.Operation {
+	op := &openapi.Operation{
+		Tags:        []string{module},
+		Summary:     doc.Summary,
+		OperationID: operationID(handler),
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	errs := slices.Clone(doc.Errors)
+	if doc.Request != nil {
+		var body *openapi.Schema
+		op.Parameters, body = builder.Request(reflect.TypeOf(doc.Request))
+		if body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{"application/json": {Schema: body}},
+			}
+		}
+		errs = append(errs, error_code.InvalidParams)
+	}
+	errs = append(errs, error_code.ServerError)
+
+	success := openapi.Ref(standardResponseSchema)
+	if doc.Response != nil {
+		success = &openapi.Schema{AllOf: []*openapi.Schema{success, {
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"data": builder.Schema(reflect.TypeOf(doc.Response))},
+		}}}
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = &openapi.Response{
+		Description: codeLine(error_code.Success),
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: success}},
+	}
+
+	// Error codes sharing an HTTP status are listed in the description of its response
+	byStatus := make(map[int][]string)
+	for _, err := range errs {
+		if line := codeLine(err); !slices.Contains(byStatus[err.StatusCode()], line) {
+			byStatus[err.StatusCode()] = append(byStatus[err.StatusCode()], line)
+		}
+	}
+	for status, lines := range byStatus {
+		op.Responses[strconv.Itoa(status)] = &openapi.Response{
+			Description: strings.Join(lines, "\n\n"),
+			Content: map[string]*openapi.MediaType{
+				"application/json":        {Schema: openapi.Ref(standardResponseSchema)},
+				handle.ProblemContentType: {Schema: openapi.Ref(problemSchema)},
+			},
+		}
+	}
+	return op
+}
+
+// describeEnvelope adds the schemas of the StandardResponse envelope, the problem details and
+// the registered error codes, the code of the former two referencing the latter
+func describeEnvelope(builder *openapi.Builder) {
+	builder.Schema(reflect.TypeOf(handle.StandardResponse{}))
+	builder.Schema(reflect.TypeOf(handle.Problem{}))
+
+	codes := &openapi.Schema{Type: "integer", Format: "int32"}
+	lines := []string{"| Code | HTTP status | Message |", "| --- | --- | --- |"}
+	for _, err := range error_code.Codes() {
+		codes.Enum = append(codes.Enum, err.Code)
+		lines = append(lines, fmt.Sprintf("| %d | %d | %s |", err.Code, err.StatusCode(), localizedMessage(err)))
+	}
+	codes.Description = strings.Join(lines, "\n")
+	builder.SetComponent(errorCodeSchema, codes)
+
+	for _, name := range []string{"handle.Meta", problemSchema} {
+		if schema := builder.Component(name); schema != nil {
+			schema.Properties["code"] = openapi.Ref(errorCodeSchema)
+		}
+	}
+}
+
+// codeLine describes an error code in the description of a response
+func codeLine(err *error_code.Error) string {
+	return fmt.Sprintf("`%d` %s", err.Code, localizedMessage(err))
+}
+
+// localizedMessage returns the message of err in the default locale of the catalog
+func localizedMessage(err *error_code.Error) string {
+	return err.Localize(i18n.DefaultLocale).Msg
+}
+
+// ruleDescription describes the custom validation rules by their messages in the catalog
+func ruleDescription(rule string) (string, bool) {
+	key := "validation." + rule
+	if _, ok := i18n.Message(i18n.DefaultLocale, key); !ok {
+		return "", false
+	}
+	return i18n.Format(i18n.DefaultLocale, key, "", "value"), true
+}
+
+// operationID derives an operation ID from the name of a handler method, e.g.
+// ".../api/http.(*userHandler).Register-fm" gives userHandler.Register. Closures have none.
+func operationID(handler string) string {
+	name := handler[strings.LastIndex(handler, "/")+1:]
+	_, name, _ = strings.Cut(name, ".")
+	name = strings.TrimSuffix(name, "-fm")
+	if strings.Contains(name, ".func") {
+		return ""
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/openapi"
+)
+
+// newDocumentedRouter registers the routes of the default modules without their services
+func newDocumentedRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+
+	router := gin.New()
+	require.NoError(t, registerOpenAPI(router, &config.OpenAPIConfig{Enabled: true}, &config.AppConfig{Name: "test", Version: "v1"}, DefaultModules()))
+	authorizer := authz.NewAuthorizer(authz.NewConfigSource(&config.AuthzConfig{}))
+	NewExampleHandler(router, nil, nil)
+	NewUserHandler(router, nil, authorizer)
+	NewSystemHandler(router, nil)
+	NewAdminHandler(router, logger.NewLevelController(zapcore.InfoLevel), logger.NewRingBuffer(10), authorizer)
+	return router
+}
+
+func TestRouteDocsMatchRoutes(t *testing.T) {
+	router := newDocumentedRouter(t)
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	for _, module := range DefaultModules() {
+		documented, ok := module.(DocumentedModule)
+		require.True(t, ok, module.Name())
+		for _, doc := range documented.RouteDocs() {
+			assert.True(t, registered[doc.Method+" "+doc.Path], "%s documents %s %s which is not registered", module.Name(), doc.Method, doc.Path)
+		}
+	}
+}
+
+func TestNewOpenAPI(t *testing.T) {
+	router := newDocumentedRouter(t)
+	doc := NewOpenAPI(router, openapi.Info{Title: "test", Version: "v1"}, DefaultModules(), openapi.DefaultPath, openapi.DefaultUIPath)
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.NotContains(t, doc.Paths, openapi.DefaultPath)
+
+	create := (*doc.Paths["/api/v1/examples"])["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, []string{"example"}, create.Tags)
+	assert.Equal(t, "exampleHandler.Create", create.OperationID)
+	assert.Equal(t, "#/components/schemas/dto.CreateExampleReq", create.RequestBody.Content["application/json"].Schema.Ref)
+	assert.ElementsMatch(t, []string{"name", "alias"}, doc.Components.Schemas["dto.CreateExampleReq"].Required)
+
+	success := create.Responses["200"].Content["application/json"].Schema
+	require.Len(t, success.AllOf, 2)
+	assert.Equal(t, "#/components/schemas/"+standardResponseSchema, success.AllOf[0].Ref)
+	assert.Equal(t, "#/components/schemas/model.Example", success.AllOf[1].Properties["data"].Ref)
+	assert.Contains(t, create.Responses["400"].Description, "`10001` invalid params")
+	assert.Contains(t, create.Responses["403"].Description, "`20005`")
+	assert.Equal(t, "#/components/schemas/"+problemSchema, create.Responses["500"].Content[handle.ProblemContentType].Schema.Ref)
+
+	// The uri field of the request is a path parameter, its other fields the body
+	update := (*doc.Paths["/api/v1/examples/{id}"])["put"]
+	require.NotNil(t, update)
+	require.Len(t, update.Parameters, 1)
+	assert.Equal(t, &openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"}}, update.Parameters[0])
+	assert.NotContains(t, doc.Components.Schemas["dto.UpdateExampleReq"].Properties, "Id")
+
+	// Custom rules are described by the catalog
+	register := doc.Components.Schemas["dto.RegisterUserReq"]
+	assert.Equal(t, "email", register.Properties["email"].Format)
+	assert.Contains(t, register.Properties["phone"].Description, "valid phone number")
+
+	logs := (*doc.Paths["/admin/logs"])["get"]
+	require.NotNil(t, logs)
+	require.Len(t, logs.Parameters, 2)
+	assert.Equal(t, "query", logs.Parameters[1].In)
+	assert.Equal(t, float64(1000), *logs.Parameters[1].Schema.Maximum)
+
+	assert.Equal(t, "#/components/schemas/"+errorCodeSchema, doc.Components.Schemas["handle.Meta"].Properties["code"].Ref)
+	assert.Contains(t, doc.Components.Schemas[errorCodeSchema].Enum, 40002)
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	router := newDocumentedRouter(t)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, openapi.DefaultPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	assert.Equal(t, "test", doc["info"].(map[string]any)["title"])
+	assert.Contains(t, doc["paths"], "/api/v1/users/{uuid}")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, openapi.DefaultUIPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+	assert.Contains(t, recorder.Body.String(), `src="/docs/assets/swagger-ui-bundle.js"`)
+	assert.NotContains(t, doc["paths"], "/docs/assets/{file}")
+
+	// The embedded assets are served under the page, nothing else from their directory
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/assets/README.md", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestServeOpenAPIWithAssetsURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+
+	router := gin.New()
+	conf := &config.OpenAPIConfig{Enabled: true, UIAssetsURL: "https://assets.example.com/swagger-ui/"}
+	require.NoError(t, registerOpenAPI(router, conf, nil, nil))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, openapi.DefaultUIPath, nil))
+	assert.Contains(t, recorder.Body.String(), `src="https://assets.example.com/swagger-ui/swagger-ui-bundle.js"`)
+	for _, route := range router.Routes() {
+		assert.NotContains(t, route.Path, openapi.UIAssetsPath)
+	}
+}
@@ -17,12 +17,13 @@ func GetPage(c *gin.Context) int {
 }
 
 func GetPageSize(c *gin.Context) int {
+	httpServer := config.Get().HTTPServer
 	pageSize := cast.ToInt(c.Query("page_size"))
 	if pageSize <= 0 {
-		return config.GlobalConfig.HTTPServer.DefaultPageSize
+		return httpServer.DefaultPageSize
 	}
-	if pageSize > config.GlobalConfig.HTTPServer.MaxPageSize {
-		return config.GlobalConfig.HTTPServer.MaxPageSize
+	if pageSize > httpServer.MaxPageSize {
+		return httpServer.MaxPageSize
 	}
 
 	return pageSize
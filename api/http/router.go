@@ -1,67 +1,106 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
 	"github.com/ntdat104/go-clean-architecture/api/http/app_context"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
 	"github.com/ntdat104/go-clean-architecture/api/http/validator/custom"
-	"github.com/ntdat104/go-clean-architecture/application/service"
 	"github.com/ntdat104/go-clean-architecture/config"
 	"github.com/ntdat104/go-clean-architecture/infra/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+	"github.com/ntdat104/go-clean-architecture/pkg/idempotency"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
+	"github.com/ntdat104/go-clean-architecture/pkg/ratelimit"
 
 	httpMiddleware "github.com/ntdat104/go-clean-architecture/api/http/middleware"
-	metricsMiddleware "github.com/ntdat104/go-clean-architecture/api/middleware"
+	httpValidator "github.com/ntdat104/go-clean-architecture/api/http/validator"
 )
 
+// NewServerRoute builds the router with the default modules on top of the given connections
 func NewServerRoute(db *sqlx.DB, rdb *redis.Client) *gin.Engine {
+	c := container.New()
+	container.Supply(c, db)
+	container.Supply(c, rdb)
+
+	router, err := NewRouter(c, DefaultModules()...)
+	if err != nil {
+		panic("Failed to build router: " + err.Error())
+	}
+	return router
+}
+
+// NewRouter builds the router, registering the providers and routes of every module.
+// Connections and overrides must already be registered in the container.
+func NewRouter(c *container.Container, modules ...Module) (*gin.Engine, error) {
+	provideCore(c)
+	for _, module := range modules {
+		module.Provide(c)
+	}
+
+	rdb, err := resolveOptional[*redis.Client](c)
+	if err != nil {
+		return nil, err
+	}
+	authorizer, err := container.Resolve[*authz.Authorizer](c)
+	if err != nil {
+		return nil, err
+	}
+
 	if config.GlobalConfig.Env.IsProd() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
+	// Let handlers passing *gin.Context as context.Context see values set on the request context
+	router.ContextWithFallback = true
+	// Believe the client address forwarded in X-Forwarded-For only from the gateways trusted with the
+	// identity headers, the peer address is used otherwise
+	var trustedProxies []string
+	if authzConfig := config.GlobalConfig.Authz; authzConfig != nil {
+		trustedProxies = authzConfig.TrustedProxies
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
 
-	// Register custom validators
+	// Register custom validators and the translations of validation messages
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		custom.RegisterValidators(v)
+		if err := httpValidator.Register(v); err != nil {
+			return nil, err
+		}
 	}
 
 	// Apply middleware
 	router.Use(gin.Recovery())
-	router.Use(httpMiddleware.Cors())
-	router.Use(httpMiddleware.RequestID())              // Add request ID middleware
-	router.Use(httpMiddleware.AppContextMiddleware())   // Add app context middleware
+	router.Use(httpMiddleware.Cors())                 // Add CORS middleware driven by http_server.cors
+	router.Use(httpMiddleware.RequestID())            // Add request ID middleware
+	router.Use(httpMiddleware.Tracing())              // Add tracing middleware continuing W3C traceparent
+	router.Use(httpMiddleware.AppContextMiddleware()) // Add app context middleware
+	// Negotiate the language of error and response messages
+	router.Use(httpMiddleware.Locale(config.GlobalConfig.I18n))
+	// Reject oversized request bodies before anything reads them
+	router.Use(httpMiddleware.BodyLimit(config.GlobalConfig.HTTPServer.MaxBodyBytes))
 	router.Use(httpMiddleware.RequestLogger())          // Add request logging middleware
 	router.Use(httpMiddleware.ErrorHandlerMiddleware()) // Add unified error handling middleware
 	// router.Use(httpMiddleware.ZapLoggerWithBody())
 
-	// Add metrics middleware for each handler
-	router.Use(func(c *gin.Context) {
-		// Use the path as a label for the metrics
-		handlerName := c.FullPath()
-		if handlerName == "" {
-			handlerName = "unknown"
-		}
+	router.Use(httpMiddleware.Metrics()) // Add request metrics labelled by route
 
-		// Record the start time
-		start := time.Now()
-
-		// Process the request
-		c.Next()
-
-		// Record metrics after request is processed
-		duration := time.Since(start)
-		statusCode := c.Writer.Status()
-
-		// Record request metrics
-		metricsMiddleware.RecordHTTPMetrics(handlerName, c.Request.Method, statusCode, duration)
-	})
+	// Request deadlines, propagated to services and repositories through the request context
+	router.Use(httpMiddleware.Timeout(config.GlobalConfig.HTTPServer))
 
 	// Health check
 	router.GET("/ping", func(c *gin.Context) {
@@ -69,25 +108,117 @@ func NewServerRoute(db *sqlx.DB, rdb *redis.Client) *gin.Engine {
 		c.String(http.StatusOK, "pong")
 	})
 
-	// Configure CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	// example
-	exampleRepo := repo.NewExampleRepo(db)
-	exampleCacheRepo := repo.NewExampleCacheRepo(rdb)
-	exampleService := service.NewExampleService(exampleRepo, exampleCacheRepo)
-	NewExampleHandler(router, exampleService)
-
-	// system
-	systemService := service.NewSystemService()
-	NewSystemHandler(router, systemService)
+	// Prometheus scraping on the main listener, ahead of authentication and rate limiting
+	if metricsConfig := config.GlobalConfig.MetricsServer; metricsConfig != nil && metricsConfig.Enabled && metricsConfig.Mode == metrics.ModeRouter {
+		router.GET(metricsPath(metricsConfig), gin.WrapH(metrics.Handler()))
+	}
 
-	return router
+	// OpenAPI document and Swagger UI, ahead of authentication and rate limiting
+	if openAPIConfig := config.GlobalConfig.OpenAPI; openAPIConfig != nil && openAPIConfig.Enabled {
+		if err := registerOpenAPI(router, openAPIConfig, config.GlobalConfig.App, modules); err != nil {
+			return nil, err
+		}
+	}
+
+	// authorization
+	if authorizer != nil {
+		authzConfig := config.GlobalConfig.Authz
+		trusted, err := httpMiddleware.NewGatewayTrust(authzConfig.TrustedProxies, authzConfig.SecretHeader, authzConfig.SharedSecret)
+		if err != nil {
+			return nil, err
+		}
+		router.Use(httpMiddleware.Authenticate(httpMiddleware.HeaderPrincipalResolver(authzConfig.SubjectHeader, authzConfig.RolesHeader, trusted)))
+	}
+
+	// rate limiting, installed while disabled too so a configuration reload can enable it;
+	// the store is chosen at startup
+	rateLimitConfig := config.GlobalConfig.RateLimit
+	router.Use(httpMiddleware.RateLimit(newRateLimiter(rdb, rateLimitConfig), rateLimitConfig))
+
+	// idempotency keys
+	if idempotencyConfig := config.GlobalConfig.Idempotency; idempotencyConfig != nil && idempotencyConfig.Enabled && rdb != nil {
+		router.Use(httpMiddleware.Idempotency(
+			idempotency.NewRedisStore(rdb),
+			config.GetDuration(idempotencyConfig.TTL),
+			config.GetDuration(idempotencyConfig.LockTTL),
+		))
+	}
+
+	// feature modules
+	for _, module := range modules {
+		if err := module.RegisterRoutes(router, c); err != nil {
+			return nil, fmt.Errorf("failed to register %s routes: %w", module.Name(), err)
+		}
+	}
+
+	return router, nil
+}
+
+// metricsPath returns the configured metrics path, defaulting to metrics.DefaultPath
+func metricsPath(metricsConfig *config.MetricsConfig) string {
+	if metricsConfig.Path == "" {
+		return metrics.DefaultPath
+	}
+	return metricsConfig.Path
+}
+
+// provideCore registers the dependencies shared by every module
+func provideCore(c *container.Container) {
+	container.Provide(c, newAuthorizer)
+}
+
+// newAuthorizer builds the authorizer from the configured policy source, or returns nil when authorization is disabled.
+// A policy that fails to load leaves the authorizer denying every request.
+func newAuthorizer(c *container.Container) (*authz.Authorizer, error) {
+	authzConfig := config.GlobalConfig.Authz
+	if authzConfig == nil || !authzConfig.Enabled {
+		return nil, nil
+	}
+
+	var source authz.PolicySource
+	switch authzConfig.Source {
+	case authz.SourceDatabase:
+		db, err := container.Resolve[*sqlx.DB](c)
+		if err != nil {
+			return nil, err
+		}
+		source = repo.NewRolePermissionRepo(db)
+	default:
+		source = authz.NewConfigSource(authzConfig)
+	}
+
+	authorizer := authz.NewAuthorizer(source)
+	if err := authorizer.Reload(context.Background()); err != nil {
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Errorf("newAuthorizer.Reload err: %v", err)
+	}
+	return authorizer, nil
+}
+
+// resolveOptional resolves T, returning its zero value when no provider is registered
+func resolveOptional[T any](c *container.Container) (T, error) {
+	value, err := container.Resolve[T](c)
+	if errors.Is(err, container.ErrNotProvided) {
+		return value, nil
+	}
+	return value, err
+}
+
+// newRateLimiter builds the configured limiter; Redis stores fall back to memory when Redis is unavailable
+func newRateLimiter(rdb *redis.Client, rateLimitConfig *config.RateLimitConfig) ratelimit.Limiter {
+	memory := ratelimit.NewMemoryLimiter()
+	if rdb == nil || (rateLimitConfig != nil && rateLimitConfig.Store == ratelimit.StoreMemory) {
+		return memory
+	}
+	return ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(rdb), memory)
+}
+
+// RegisterReadiness exposes GET /ready, answering 503 while ready reports false so load balancers stop routing here
+func RegisterReadiness(router *gin.Engine, ready func() bool) {
+	router.GET("/ready", func(c *gin.Context) {
+		if !ready() {
+			handle.NewResponse(c).ToErrorResponse(error_code.ServiceUnavailable)
+			return
+		}
+		c.String(http.StatusOK, "ready")
+	})
 }
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	domainRepo "github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// newTestDB opens a database the routes can be registered with, never reached while they are
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sql.Open("mysql", "test@tcp(127.0.0.1:1)/test")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return sqlx.NewDb(db, "mysql")
+}
+
+// useTestConfig makes a minimal valid configuration the global one for the test
+func useTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{
+		App:        &config.AppConfig{Name: "test"},
+		HTTPServer: &config.HttpServerConfig{Addr: ":8080", DefaultPageSize: 10, MaxPageSize: 100},
+		Log:        &config.LogConfig{},
+	}
+	t.Cleanup(func() { config.GlobalConfig = previous })
+	return config.GlobalConfig
+}
+
+func TestNewRouterWithoutRedis(t *testing.T) {
+	useTestConfig(t)
+	db := newTestDB(t)
+
+	for name, supply := range map[string]func(c *container.Container){
+		"not provided": func(*container.Container) {},
+		"nil client":   func(c *container.Container) { container.Supply[*redis.Client](c, nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := container.New()
+			container.Supply(c, db)
+			supply(c)
+
+			_, err := NewRouter(c, DefaultModules()...)
+			require.NoError(t, err)
+
+			cache, err := container.Resolve[domainRepo.IExampleCacheRepo](c)
+			require.NoError(t, err)
+			assert.Nil(t, cache)
+		})
+	}
+}
+
+func TestRateLimitEnabledByReload(t *testing.T) {
+	conf := useTestConfig(t)
+	c := container.New()
+	container.Supply(c, newTestDB(t))
+	router, err := NewRouter(c, DefaultModules()...)
+	require.NoError(t, err)
+
+	serve := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNotFound, serve())
+	assert.Equal(t, http.StatusNotFound, serve())
+
+	reloaded := *conf
+	reloaded.RateLimit = &config.RateLimitConfig{Enabled: true, Store: "memory", Limit: 1, Window: "1m"}
+	_, err = config.Update(&reloaded)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve())
+}
+
+func TestRateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	conf := useTestConfig(t)
+	conf.RateLimit = &config.RateLimitConfig{Enabled: true, Store: "memory", Limit: 1, Window: "1m"}
+	c := container.New()
+	container.Supply(c, newTestDB(t))
+	router, err := NewRouter(c, DefaultModules()...)
+	require.NoError(t, err)
+
+	serve := func(forwardedFor string) int {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, request)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNotFound, serve("203.0.113.1"))
+	// The peer is no trusted proxy: another forwarded address is the same client
+	assert.Equal(t, http.StatusTooManyRequests, serve("203.0.113.2"))
+}
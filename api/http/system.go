@@ -1,9 +1,13 @@
 package http
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	appDto "github.com/ntdat104/go-clean-architecture/application/dto"
 	"github.com/ntdat104/go-clean-architecture/application/service"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
 )
 
 type SystemHandler interface {
@@ -34,3 +38,32 @@ func (h *systemHandler) GetTime(ctx *gin.Context) {
 	response := handle.NewResponse(ctx)
 	response.ToResponse(h.systemService.GetTime())
 }
+
+// SystemModule wires the system endpoints
+type SystemModule struct{}
+
+func (SystemModule) Name() string {
+	return "system"
+}
+
+func (SystemModule) Provide(c *container.Container) {
+	container.Provide(c, func(*container.Container) (service.SystemService, error) {
+		return service.NewSystemService(), nil
+	})
+}
+
+func (SystemModule) RegisterRoutes(router *gin.Engine, c *container.Container) error {
+	systemService, err := container.Resolve[service.SystemService](c)
+	if err != nil {
+		return err
+	}
+	NewSystemHandler(router, systemService)
+	return nil
+}
+
+// RouteDocs describes the system routes in the OpenAPI document
+func (SystemModule) RouteDocs() []RouteDoc {
+	return []RouteDoc{
+		{Method: http.MethodGet, Path: "/api/v1/system/time", Summary: "Get the time of the server", Response: appDto.SystemTime{}},
+	}
+}
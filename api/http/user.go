@@ -0,0 +1,218 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/ntdat104/go-clean-architecture/api/dto"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/handle"
+	"github.com/ntdat104/go-clean-architecture/api/http/validator"
+	"github.com/ntdat104/go-clean-architecture/application/service"
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+	domainRepo "github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/infra/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+type UserHandler interface {
+	Register(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	FindByEmail(ctx *gin.Context)
+}
+
+type userHandler struct {
+	router      *gin.Engine
+	userService service.IUserService
+	authorizer  *authz.Authorizer
+}
+
+// NewUserHandler registers the user endpoints. Without an authorizer only registration is served,
+// the profiles are never exposed unauthenticated.
+func NewUserHandler(router *gin.Engine, userService service.IUserService, authorizer *authz.Authorizer) {
+	h := &userHandler{
+		router:      router,
+		userService: userService,
+		authorizer:  authorizer,
+	}
+	h.initRoutes()
+}
+
+func (h *userHandler) initRoutes() {
+	v1 := h.router.Group("/api/v1/users")
+	v1.POST("", h.Register)
+	// Profiles hold personal data: they are never served unauthenticated
+	if h.authorizer == nil {
+		logger.SugarForComponent(context.Background(), logger.ComponentHTTP).Infof("User profile endpoints disabled: they require authz to be enabled")
+		return
+	}
+
+	read := requirePermission(h.authorizer, service.PermUsersRead)
+	write := requirePermission(h.authorizer, service.PermUsersWrite)
+	// Users change their own profile, managers any profile
+	owner := requireOwnerOrPermission(h.authorizer, "uuid", service.PermUsersManage)
+	{
+		v1.GET("/:uuid", read, h.Get)
+		v1.PUT("/:uuid", write, owner, h.Update)
+		v1.GET("/email/:email", read, h.FindByEmail)
+	}
+}
+
+// Register handles the registration of a new user.
+func (h *userHandler) Register(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+	body := dto.RegisterUserReq{}
+
+	if valid, errs := validator.BindAndValid(ctx, &body, ctx.ShouldBindJSON); !valid {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Register.BindAndValid errs: %v", errs)
+		err := errs.ToError()
+		response.ToErrorResponse(err)
+		return
+	}
+
+	user, err := h.userService.Register(ctx, body.Name, body.Email, body.Phone, body.Password)
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Register.userService.Register err: %v", err)
+		response.ToErrorResponse(userErrorCode(err))
+		return
+	}
+
+	response.ToResponse(toUserResp(user))
+}
+
+// Get handles retrieving a user by UUID.
+func (h *userHandler) Get(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+
+	user, err := h.userService.GetByUUID(ctx, ctx.Param("uuid"))
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Get.userService.GetByUUID err: %v", err)
+		response.ToErrorResponse(userErrorCode(err))
+		return
+	}
+
+	response.ToResponse(toUserResp(user))
+}
+
+// FindByEmail handles retrieving a user by email.
+func (h *userHandler) FindByEmail(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+
+	user, err := h.userService.GetByEmail(ctx, ctx.Param("email"))
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("FindByEmail.userService.GetByEmail err: %v", err)
+		response.ToErrorResponse(userErrorCode(err))
+		return
+	}
+
+	response.ToResponse(toUserResp(user))
+}
+
+// Update handles updating the profile of an existing user.
+func (h *userHandler) Update(ctx *gin.Context) {
+	response := handle.NewResponse(ctx)
+	body := dto.UpdateUserReq{}
+
+	if valid, errs := validator.BindAndValid(ctx, &body, ctx.ShouldBindJSON); !valid {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Update.BindAndValid errs: %v", errs)
+		err := errs.ToError()
+		response.ToErrorResponse(err)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(ctx, ctx.Param("uuid"), body.Name, body.Phone)
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Errorf("Update.userService.UpdateProfile err: %v", err)
+		response.ToErrorResponse(userErrorCode(err))
+		return
+	}
+
+	response.ToResponse(toUserResp(user))
+}
+
+// userErrorCode maps user service errors to API error codes
+func userErrorCode(err error) *error_code.Error {
+	switch {
+	case model.IsUserNameTakenError(err):
+		return error_code.UserNameExist
+	case model.IsUserEmailTakenError(err):
+		return error_code.AccountExist
+	case model.IsUserNotFoundError(err):
+		return error_code.NotFound
+	default:
+		return handle.FromAppError(err, error_code.ServerError)
+	}
+}
+
+func toUserResp(user *model.User) *dto.UserResp {
+	return &dto.UserResp{
+		Uuid:      user.Uuid,
+		Name:      user.Name,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// UserModule wires the user feature
+type UserModule struct{}
+
+func (UserModule) Name() string {
+	return "user"
+}
+
+func (UserModule) Provide(c *container.Container) {
+	container.Provide(c, func(c *container.Container) (domainRepo.IUserRepo, error) {
+		db, err := container.Resolve[*sqlx.DB](c)
+		if err != nil {
+			return nil, err
+		}
+		return repo.NewUserRepo(db), nil
+	})
+	container.Provide(c, func(c *container.Container) (service.IUserService, error) {
+		userRepo, err := container.Resolve[domainRepo.IUserRepo](c)
+		if err != nil {
+			return nil, err
+		}
+		authorizer, err := container.Resolve[*authz.Authorizer](c)
+		if err != nil {
+			return nil, err
+		}
+		return service.NewUserService(userRepo, authorizer), nil
+	})
+}
+
+func (UserModule) RegisterRoutes(router *gin.Engine, c *container.Container) error {
+	userService, err := container.Resolve[service.IUserService](c)
+	if err != nil {
+		return err
+	}
+	authorizer, err := container.Resolve[*authz.Authorizer](c)
+	if err != nil {
+		return err
+	}
+	NewUserHandler(router, userService, authorizer)
+	return nil
+}
+
+// RouteDocs describes the user routes in the OpenAPI document
+func (UserModule) RouteDocs() []RouteDoc {
+	userErrors := append([]*error_code.Error{error_code.NotFound}, permissionErrors...)
+	return []RouteDoc{
+		{Method: http.MethodPost, Path: "/api/v1/users", Summary: "Register a user",
+			Request: dto.RegisterUserReq{}, Response: dto.UserResp{},
+			Errors: []*error_code.Error{error_code.AccountExist, error_code.UserNameExist}},
+		{Method: http.MethodGet, Path: "/api/v1/users/:uuid", Summary: "Get a user by UUID",
+			Response: dto.UserResp{}, Errors: userErrors},
+		{Method: http.MethodPut, Path: "/api/v1/users/:uuid", Summary: "Update a user",
+			Request: dto.UpdateUserReq{}, Response: dto.UserResp{}, Errors: append(userErrors, error_code.UserNameExist)},
+		{Method: http.MethodGet, Path: "/api/v1/users/email/:email", Summary: "Find a user by email",
+			Response: dto.UserResp{}, Errors: userErrors},
+	}
+}
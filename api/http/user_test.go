@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/api/http/middleware"
+	"github.com/ntdat104/go-clean-architecture/api/http/validator/custom"
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// stubUserService returns a user with the requested UUID
+type stubUserService struct{}
+
+func (stubUserService) Register(_ context.Context, name, email, phone, _ string) (*model.User, error) {
+	return &model.User{Uuid: "new", Name: name, Email: email, Phone: phone}, nil
+}
+
+func (stubUserService) UpdateProfile(_ context.Context, uuid, name, phone string) (*model.User, error) {
+	return &model.User{Uuid: uuid, Name: name, Phone: phone}, nil
+}
+
+func (stubUserService) GetByUUID(_ context.Context, uuid string) (*model.User, error) {
+	return &model.User{Uuid: uuid}, nil
+}
+
+func (stubUserService) GetByEmail(_ context.Context, email string) (*model.User, error) {
+	return &model.User{Uuid: "found", Email: email}, nil
+}
+
+func newUserRouter(t *testing.T, authorizer *authz.Authorizer) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+
+	// httptest requests come from 192.0.2.1, standing for the gateway
+	trusted, err := middleware.NewGatewayTrust([]string{"192.0.2.1"}, "", "")
+	require.NoError(t, err)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		custom.RegisterValidators(v)
+	}
+	router := gin.New()
+	router.Use(
+		middleware.AppContextMiddleware(),
+		middleware.Authenticate(middleware.HeaderPrincipalResolver("", "", trusted)),
+	)
+	NewUserHandler(router, stubUserService{}, authorizer)
+	return router
+}
+
+func serveUser(router *gin.Engine, method, target, subject, roles, body string) int {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if subject != "" {
+		request.Header.Set(middleware.DefaultSubjectHeader, subject)
+		request.Header.Set(middleware.DefaultRolesHeader, roles)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestUserProfilesRequireAuthorizer(t *testing.T) {
+	router := newUserRouter(t, nil)
+
+	assert.Equal(t, http.StatusNotFound, serveUser(router, http.MethodGet, "/api/v1/users/u-1", "", "", ""))
+	assert.Equal(t, http.StatusNotFound, serveUser(router, http.MethodPut, "/api/v1/users/u-1", "", "", `{"name":"alice"}`))
+	assert.Equal(t, http.StatusNotFound, serveUser(router, http.MethodGet, "/api/v1/users/email/a@example.com", "", "", ""))
+}
+
+func TestUserUpdateRequiresOwner(t *testing.T) {
+	authorizer := authz.NewAuthorizer(authz.NewConfigSource(&config.AuthzConfig{
+		Roles: map[string][]string{
+			"member":  {"users:read", "users:write"},
+			"support": {"users:read", "users:write", "users:manage"},
+		},
+	}))
+	require.NoError(t, authorizer.Reload(context.Background()))
+	router := newUserRouter(t, authorizer)
+
+	update := func(uuid, subject, roles string) int {
+		return serveUser(router, http.MethodPut, "/api/v1/users/"+uuid, subject, roles, `{"name":"alice"}`)
+	}
+	assert.Equal(t, http.StatusUnauthorized, update("u-1", "", ""))
+	assert.Equal(t, http.StatusOK, update("u-1", "u-1", "member"))
+	assert.Equal(t, http.StatusForbidden, update("u-2", "u-1", "member"))
+	assert.Equal(t, http.StatusOK, update("u-2", "u-1", "support"))
+	// The profile of its own user still requires the write permission
+	assert.Equal(t, http.StatusForbidden, update("u-1", "u-1", "guest"))
+}
+
+func TestUserRegisterRejectsLongPassword(t *testing.T) {
+	router := newUserRouter(t, nil)
+
+	register := func(password string) int {
+		body := `{"name":"alice","email":"alice@example.com","password":"` + password + `"}`
+		return serveUser(router, http.MethodPost, "/api/v1/users", "", "", body)
+	}
+	assert.Equal(t, http.StatusOK, register("Test123!"+strings.Repeat("a", 64)))
+	// bcrypt cannot hash more than 72 bytes: this is invalid input, not a server error
+	assert.Equal(t, http.StatusBadRequest, register("Test123!"+strings.Repeat("a", 65)))
+}
@@ -4,12 +4,16 @@ import (
 	"regexp"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/password"
 )
 
-// Min password length constant
+// Password length constants
 const (
 	// MinPasswordLength defines the minimum length for password validation
 	MinPasswordLength = 8
+	// MaxPasswordLength defines the maximum length in bytes for password validation, the most bcrypt hashes
+	MaxPasswordLength = password.MaxLength
 )
 
 // RegisterValidators registers custom validators
@@ -43,16 +47,16 @@ func validateUsername(fl validator.FieldLevel) bool {
 
 // validatePassword validates passwords
 func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-	// Password must be at least 8 characters long and contain:
+	value := fl.Field().String()
+	// Password must be 8 to 72 bytes long and contain:
 	// - At least one uppercase letter
 	// - At least one lowercase letter
 	// - At least one number
 	// - At least one special character
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
-	hasSpecial := regexp.MustCompile(`[!@#$%^&*]`).MatchString(password)
-	hasLength := len(password) >= MinPasswordLength
+	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(value)
+	hasLower := regexp.MustCompile(`[a-z]`).MatchString(value)
+	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(value)
+	hasSpecial := regexp.MustCompile(`[!@#$%^&*]`).MatchString(value)
+	hasLength := len(value) >= MinPasswordLength && len(value) <= MaxPasswordLength
 	return hasUpper && hasLower && hasNumber && hasSpecial && hasLength
 }
@@ -1,6 +1,7 @@
 package custom
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -122,6 +123,9 @@ func TestValidatePassword(t *testing.T) {
 		{"Invalid password - no number", "TestTest!", true},
 		{"Invalid password - no special char", "Test1234", true},
 		{"Invalid password - too short", "Te1!", true},
+		{"Valid password - longest", "Test123!" + strings.Repeat("a", 64), false},
+		{"Invalid password - too long", "Test123!" + strings.Repeat("a", 65), true},
+		{"Invalid password - too many bytes", "Test123!" + strings.Repeat("é", 33), true},
 		{"Invalid password - empty", "", true},
 	}
 
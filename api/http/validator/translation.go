@@ -0,0 +1,85 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/vi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	vi_translations "github.com/go-playground/validator/v10/translations/vi"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
+)
+
+// customRules are the rules of the custom package, translated by the i18n catalog under "validation.<rule>"
+var customRules = []string{"phone", "username", "password"}
+
+// universal holds the translators registered by Register
+var universal atomic.Pointer[ut.UniversalTranslator]
+
+// Register names the fields of v as the request does, by their json, form or uri tag, and registers
+// the translations of the built-in rules in every supported language; the custom rules are
+// translated by the i18n catalog
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	english := en.New()
+	translators := ut.New(english, english, vi.New())
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"vi": vi_translations.RegisterDefaultTranslations,
+	}
+
+	for language, registerDefaults := range defaults {
+		trans, _ := translators.GetTranslator(language)
+		if err := registerDefaults(v, trans); err != nil {
+			return err
+		}
+
+		for _, rule := range customRules {
+			err := v.RegisterTranslation(rule, trans, func(ut.Translator) error { return nil }, translateFieldError)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	universal.Store(translators)
+	return nil
+}
+
+// Translator returns the translator of the locale of the request, see i18n.RequestLocale.
+// It is nil until Register is called.
+func Translator(c *gin.Context) ut.Translator {
+	translators := universal.Load()
+	if translators == nil {
+		return nil
+	}
+
+	trans, _ := translators.FindTranslator(i18n.RequestLocale(c.Request))
+	return trans
+}
+
+// translateFieldError translates a custom rule with the field and the rule parameter
+func translateFieldError(trans ut.Translator, fieldError validator.FieldError) string {
+	message, ok := i18n.Message(trans.Locale(), "validation."+fieldError.Tag())
+	if !ok {
+		return fieldError.Error()
+	}
+	return fmt.Sprintf(message, fieldError.Field(), fieldError.Param())
+}
+
+// fieldName names a field by its json, form or uri tag, or else by its Go name
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
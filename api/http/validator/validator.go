@@ -1,19 +1,37 @@
 package validator
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 
-	"github.com/fatih/structtag"
 	"github.com/gin-gonic/gin"
-	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
 )
 
+// MessageTagKey is the struct tag replacing the messages of every rule failed by a field,
+// either a key of the i18n catalog or a literal message
 const MessageTagKey = "message"
 
+// Rules of the errors raised while decoding a request rather than validating it
+const (
+	RuleType  = "type"
+	RuleParse = "parse"
+	// RuleTooLarge is raised when the body exceeds the limit of middleware.BodyLimit
+	RuleTooLarge = "too_large"
+)
+
+// ValidError is a request field failing a validation rule
 type ValidError struct {
-	Key     string
+	Key     string // Namespace of the field, e.g. CreateExampleReq.name
+	Field   string // Path of the field in the request, e.g. items[0].name
+	Rule    string // Failed rule, e.g. required
+	Param   string // Parameter of the rule, e.g. 64 for max=64
 	Message string
 }
 
@@ -31,58 +49,123 @@ func (v *ValidErrors) Errors() []string {
 	return errs
 }
 
+// Fields returns the errors as the field errors of an API error
+func (v *ValidErrors) Fields() []error_code.FieldError {
+	fields := make([]error_code.FieldError, 0, len(*v))
+	for _, err := range *v {
+		fields = append(fields, error_code.FieldError{
+			Field:   err.Field,
+			Rule:    err.Rule,
+			Param:   err.Param,
+			Message: err.Message,
+		})
+	}
+	return fields
+}
+
+// ToError returns the API error answering the request: RequestEntityTooLarge when the body
+// exceeded the size limit, InvalidParams with the failed fields otherwise
+func (v *ValidErrors) ToError() *error_code.Error {
+	for _, err := range *v {
+		if err.Rule == RuleTooLarge {
+			return error_code.RequestEntityTooLarge
+		}
+	}
+	return error_code.InvalidParams.WithFields(v.Fields()...)
+}
+
 func (v *ValidErrors) Error() string {
 	return strings.Join(v.Errors(), ",")
 }
 
+// BindAndValid binds the request to obj and validates it, returning one error per failed rule in
+// field order. Messages are translated to the locale of the request, see i18n.RequestLocale.
 func BindAndValid(c *gin.Context, obj any, binder func(any) error) (bool, ValidErrors) {
-	var errs ValidErrors
 	err := binder(obj)
-	if err != nil {
-		v := c.Value("trans")
-		trans, _ := v.(ut.Translator)
-		verrs, ok := err.(validator.ValidationErrors)
-		if !ok {
-			errs = append(errs, &ValidError{
-				Key:     "unknown error",
-				Message: err.Error(),
-			})
-
-			return false, errs
+	if err == nil {
+		return true, nil
+	}
+
+	locale := i18n.RequestLocale(c.Request)
+	trans := Translator(c)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return false, ValidErrors{decodeError(err, locale)}
+	}
+
+	errs := make(ValidErrors, 0, len(verrs))
+	for _, fieldError := range verrs {
+		// The namespace starts with the struct name, e.g. CreateExampleReq.items[0].name
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		validError := &ValidError{
+			Key:     fieldError.Namespace(),
+			Field:   field,
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: fieldError.Translate(trans),
+		}
+		if message := messageTag(obj, fieldError.StructNamespace()); message != "" {
+			validError.Message = i18n.Format(locale, message, message)
+		}
+		errs = append(errs, validError)
+	}
+	return false, errs
+}
+
+// decodeError describes an error raised before validation, such as malformed JSON
+func decodeError(err error, locale string) *ValidError {
+	// Bodies without a declared length are only cut off by http.MaxBytesReader while decoding
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return &ValidError{
+			Key:     "body",
+			Rule:    RuleTooLarge,
+			Param:   strconv.FormatInt(maxBytesError.Limit, 10),
+			Message: err.Error(),
+		}
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return &ValidError{
+			Key:     typeError.Field,
+			Field:   typeError.Field,
+			Rule:    RuleType,
+			Param:   typeError.Type.String(),
+			Message: i18n.Format(locale, "validation."+RuleType, "%[1]s must be a %[2]s", typeError.Field, typeError.Type.String()),
+		}
+	}
+
+	return &ValidError{
+		Key:     "unknown error",
+		Rule:    RuleParse,
+		Message: err.Error(),
+	}
+}
+
+// messageTag returns the message tag of the field at the Go struct namespace of obj,
+// e.g. CreateOrderReq.Items[0].Name, following pointers, slices and maps
+func messageTag(obj any, structNamespace string) string {
+	segments := strings.Split(structNamespace, ".")
+	t := reflect.TypeOf(obj)
+
+	var field reflect.StructField
+	for _, segment := range segments[1:] {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return ""
 		}
 
-		for key, value := range verrs.Translate(trans) {
-			validError := &ValidError{
-				Key:     key,
-				Message: value,
-			}
-
-			// get message tag, and replace valid Error.Message with message from tag
-			tmpKey := strings.Split(key, ".")
-			fieldName := tmpKey[len(tmpKey)-1]
-			t := reflect.TypeOf(obj)
-			k := t.Kind()
-			for k == reflect.Ptr {
-				t = t.Elem()
-				k = t.Kind()
-			}
-			field, exists := t.FieldByName(fieldName)
-			var tag reflect.StructTag
-			if exists {
-				tag = field.Tag
-			}
-			if tag != "" {
-				tags, _ := structtag.Parse(string(tag))
-				messageTag, _ := tags.Get(MessageTagKey)
-				if messageTag != nil && messageTag.Name != "" {
-					validError.Message = messageTag.Name
-				}
-			}
-
-			errs = append(errs, validError)
+		name, _, _ := strings.Cut(segment, "[")
+		var exists bool
+		if field, exists = t.FieldByName(name); !exists {
+			return ""
 		}
-		return false, errs
+		t = field.Type
 	}
 
-	return true, nil
+	// Read as a whole, messages may contain commas
+	return field.Tag.Get(MessageTagKey)
 }
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ntdat104/go-clean-architecture/api/error_code"
+	"github.com/ntdat104/go-clean-architecture/api/http/validator/custom"
+)
+
+type orderItem struct {
+	Name     string `json:"name" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1,max=10" message:"quantity must be between 1 and 10, inclusive"`
+}
+
+type createOrderReq struct {
+	Phone string      `json:"phone" validate:"required,phone"`
+	Items []orderItem `json:"items" validate:"required,dive"`
+}
+
+func bindOrder(t *testing.T, acceptLanguage, body string) ValidErrors {
+	t.Helper()
+	validate := validator.New()
+	custom.RegisterValidators(validate)
+	require.NoError(t, Register(validate))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	c.Request.Header.Set("Accept-Language", acceptLanguage)
+
+	valid, errs := BindAndValid(c, &createOrderReq{}, func(obj any) error {
+		if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+			return err
+		}
+		return validate.Struct(obj)
+	})
+	assert.False(t, valid)
+	return errs
+}
+
+func TestBindAndValidReportsFields(t *testing.T) {
+	errs := bindOrder(t, "en-US,en;q=0.9", `{"phone":"12","items":[{"name":"tea","quantity":1},{"quantity":11}]}`)
+
+	assert.Equal(t, []error_code.FieldError{
+		{Field: "phone", Rule: "phone", Message: "phone must be a valid phone number"},
+		{Field: "items[1].name", Rule: "required", Message: "name is a required field"},
+		{Field: "items[1].quantity", Rule: "max", Param: "10", Message: "quantity must be between 1 and 10, inclusive"},
+	}, errs.Fields())
+	assert.Equal(t, "createOrderReq.items[1].name", errs[1].Key)
+}
+
+func TestBindAndValidTranslates(t *testing.T) {
+	errs := bindOrder(t, "fr;q=0.9, vi-VN;q=0.8", `{"phone":"1234567890"}`)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "items", errs[0].Field)
+	assert.Equal(t, "items không được bỏ trống", errs[0].Message)
+}
+
+func TestBindAndValidDecodeErrors(t *testing.T) {
+	errs := bindOrder(t, "", `{"phone":12}`)
+	assert.Equal(t, []error_code.FieldError{
+		{Field: "phone", Rule: RuleType, Param: "string", Message: "phone must be a string"},
+	}, errs.Fields())
+
+	errs = bindOrder(t, "", `{"phone":`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, RuleParse, errs[0].Rule)
+	assert.Empty(t, errs[0].Field)
+}
+
+func TestBindAndValidTranslatesMessageTags(t *testing.T) {
+	type registerReq struct {
+		Email string `json:"email" validate:"required,email" message:"validation.user.email"`
+	}
+	validate := validator.New()
+	require.NoError(t, Register(validate))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/register?lang=vi", nil)
+	_, errs := BindAndValid(c, &registerReq{Email: "nope"}, validate.Struct)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "email phải là địa chỉ email hợp lệ", errs[0].Message)
+}
@@ -4,9 +4,19 @@ import (
 	"context"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/ntdat104/go-clean-architecture/domain/model"
 	"github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
+)
+
+// Example permissions
+const (
+	PermExamplesRead  authz.Permission = "examples:read"
+	PermExamplesWrite authz.Permission = "examples:write"
 )
 
 type IExampleService interface {
@@ -20,35 +30,53 @@ type IExampleService interface {
 type exampleService struct {
 	exampleRepo      repo.IExampleRepo
 	exampleCacheRepo repo.IExampleCacheRepo
+	authorizer       *authz.Authorizer
 }
 
-func NewExampleService(exampleRepo repo.IExampleRepo, exampleCacheRepo repo.IExampleCacheRepo) IExampleService {
+// NewExampleService creates the example service; a nil authorizer disables permission checks
+func NewExampleService(exampleRepo repo.IExampleRepo, exampleCacheRepo repo.IExampleCacheRepo, authorizer *authz.Authorizer) IExampleService {
 	return &exampleService{
 		exampleRepo:      exampleRepo,
 		exampleCacheRepo: exampleCacheRepo,
+		authorizer:       authorizer,
+	}
+}
+
+// authorize checks the caller in ctx holds the permission when authorization is enabled
+func (s exampleService) authorize(ctx context.Context, perm authz.Permission) error {
+	if s.authorizer == nil {
+		return nil
 	}
+	return s.authorizer.Authorize(ctx, perm)
 }
 
 // Create creates a new example
-func (s exampleService) Create(ctx context.Context, name string, alias string) (*model.Example, error) {
+func (s exampleService) Create(ctx context.Context, name string, alias string) (_ *model.Example, err error) {
+	ctx, span := tracing.Start(ctx, "exampleService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.authorize(ctx, PermExamplesWrite); err != nil {
+		return nil, err
+	}
+
 	// Create a new example entity
 	example, err := model.NewExample(name, alias)
 	if err != nil {
-		logger.SugaredLogger.Errorf("Invalid example data: %v", err)
+		logger.SugarForComponent(ctx, logger.ComponentService).Errorf("Invalid example data: %v", err)
 		return nil, fmt.Errorf("invalid example data: %w", err)
 	}
 
 	// Persist the entity
 	createdExample, err := s.exampleRepo.Create(ctx, example)
 	if err != nil {
-		logger.SugaredLogger.Errorf("Failed to create example: %v", err)
+		logger.SugarForComponent(ctx, logger.ComponentService).Errorf("Failed to create example: %v", err)
 		return nil, fmt.Errorf("failed to create example: %w", err)
 	}
 
 	// Update cache if available
 	if s.exampleCacheRepo != nil {
 		if err := s.exampleCacheRepo.Set(ctx, createdExample); err != nil {
-			logger.SugaredLogger.Warnf("Failed to update cache: %v", err)
+			logger.SugarForComponent(ctx, logger.ComponentService).Warnf("Failed to update cache: %v", err)
 		}
 	}
 
@@ -56,9 +84,16 @@ func (s exampleService) Create(ctx context.Context, name string, alias string) (
 }
 
 // Delete deletes an example by ID
-func (s exampleService) Delete(ctx context.Context, id int) error {
+func (s exampleService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "exampleService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.authorize(ctx, PermExamplesWrite); err != nil {
+		return err
+	}
+
 	// Get the example to be deleted
-	_, err := s.exampleRepo.GetByID(ctx, id)
+	_, err = s.exampleRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("example not found: %w", err)
 	}
@@ -71,7 +106,7 @@ func (s exampleService) Delete(ctx context.Context, id int) error {
 	// Invalidate cache if available
 	if s.exampleCacheRepo != nil {
 		if err := s.exampleCacheRepo.Delete(ctx, id); err != nil {
-			logger.SugaredLogger.Warnf("Failed to invalidate cache: %v", err)
+			logger.SugarForComponent(ctx, logger.ComponentService).Warnf("Failed to invalidate cache: %v", err)
 		}
 	}
 
@@ -79,7 +114,14 @@ func (s exampleService) Delete(ctx context.Context, id int) error {
 }
 
 // Update updates an existing example
-func (s exampleService) Update(ctx context.Context, id int, name string, alias string) error {
+func (s exampleService) Update(ctx context.Context, id int, name string, alias string) (err error) {
+	ctx, span := tracing.Start(ctx, "exampleService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.authorize(ctx, PermExamplesWrite); err != nil {
+		return err
+	}
+
 	// Get the example to be updated
 	example, err := s.exampleRepo.GetByID(ctx, id)
 	if err != nil {
@@ -99,7 +141,7 @@ func (s exampleService) Update(ctx context.Context, id int, name string, alias s
 	// Update cache if available
 	if s.exampleCacheRepo != nil {
 		if err := s.exampleCacheRepo.Set(ctx, example); err != nil {
-			logger.SugaredLogger.Warnf("Failed to update cache: %v", err)
+			logger.SugarForComponent(ctx, logger.ComponentService).Warnf("Failed to update cache: %v", err)
 		}
 	}
 
@@ -107,13 +149,20 @@ func (s exampleService) Update(ctx context.Context, id int, name string, alias s
 }
 
 // Get retrieves an example by ID
-func (s exampleService) Get(ctx context.Context, id int) (*model.Example, error) {
+func (s exampleService) Get(ctx context.Context, id int) (_ *model.Example, err error) {
+	ctx, span := tracing.Start(ctx, "exampleService.Get")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.authorize(ctx, PermExamplesRead); err != nil {
+		return nil, err
+	}
+
 	if s.exampleCacheRepo != nil {
 		example, err := s.exampleCacheRepo.GetByID(ctx, id)
 		if err == nil {
 			return example, nil
 		}
-		logger.SugaredLogger.Debugf("Cache miss for example ID %d: %v", id, err)
+		logger.ForComponent(ctx, logger.ComponentService).Debug("Cache miss for example by ID", zap.Int("id", id), zap.Error(err))
 	}
 
 	// Get from repository
@@ -125,7 +174,7 @@ func (s exampleService) Get(ctx context.Context, id int) (*model.Example, error)
 	// Update cache if available
 	if s.exampleCacheRepo != nil {
 		if err := s.exampleCacheRepo.Set(ctx, example); err != nil {
-			logger.SugaredLogger.Warnf("Failed to update cache: %v", err)
+			logger.SugarForComponent(ctx, logger.ComponentService).Warnf("Failed to update cache: %v", err)
 		}
 	}
 
@@ -133,14 +182,21 @@ func (s exampleService) Get(ctx context.Context, id int) (*model.Example, error)
 }
 
 // FindByName retrieves an example by name
-func (s exampleService) FindByName(ctx context.Context, name string) (*model.Example, error) {
+func (s exampleService) FindByName(ctx context.Context, name string) (_ *model.Example, err error) {
+	ctx, span := tracing.Start(ctx, "exampleService.FindByName")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.authorize(ctx, PermExamplesRead); err != nil {
+		return nil, err
+	}
+
 	// Try to get from cache first
 	if s.exampleCacheRepo != nil {
 		example, err := s.exampleCacheRepo.GetByName(ctx, name)
 		if err == nil {
 			return example, nil
 		}
-		logger.SugaredLogger.Debugf("Cache miss for example name %s: %v", name, err)
+		logger.ForComponent(ctx, logger.ComponentService).Debug("Cache miss for example by name", zap.String("name", name), zap.Error(err))
 	}
 
 	// Get from repository
@@ -152,7 +208,7 @@ func (s exampleService) FindByName(ctx context.Context, name string) (*model.Exa
 	// Update cache if available
 	if s.exampleCacheRepo != nil {
 		if err := s.exampleCacheRepo.Set(ctx, example); err != nil {
-			logger.SugaredLogger.Warnf("Failed to update cache: %v", err)
+			logger.SugarForComponent(ctx, logger.ComponentService).Warnf("Failed to update cache: %v", err)
 		}
 	}
 
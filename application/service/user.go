@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+	"github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/authz"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/password"
+)
+
+// User permissions
+const (
+	PermUsersRead  authz.Permission = "users:read"
+	PermUsersWrite authz.Permission = "users:write"
+	// PermUsersManage allows changing the profile of other users, not only one's own
+	PermUsersManage authz.Permission = "users:manage"
+)
+
+type IUserService interface {
+	Register(ctx context.Context, name, email, phone, plainPassword string) (*model.User, error)
+	UpdateProfile(ctx context.Context, uuid, name, phone string) (*model.User, error)
+	GetByUUID(ctx context.Context, uuid string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+}
+
+type userService struct {
+	userRepo   repo.IUserRepo
+	authorizer *authz.Authorizer
+}
+
+// NewUserService creates the user service; a nil authorizer disables permission checks
+func NewUserService(userRepo repo.IUserRepo, authorizer *authz.Authorizer) IUserService {
+	return &userService{
+		userRepo:   userRepo,
+		authorizer: authorizer,
+	}
+}
+
+// authorize checks the caller in ctx holds the permission when authorization is enabled
+func (s userService) authorize(ctx context.Context, perm authz.Permission) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	return s.authorizer.Authorize(ctx, perm)
+}
+
+// Register creates a new user account with a hashed password
+func (s userService) Register(ctx context.Context, name, email, phone, plainPassword string) (*model.User, error) {
+	// Reject duplicates before paying for the password hash. This is a fast path only: a concurrent
+	// registration may still win, the repository then reports the conflict from the unique indexes.
+	if err := s.ensureAvailable(ctx, name, email); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := password.Hash(plainPassword)
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentService).Errorf("Failed to hash password: %v", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := model.NewUser(name, email, phone, passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user data: %w", err)
+	}
+
+	createdUser, err := s.userRepo.Create(ctx, user)
+	if err != nil {
+		logger.SugarForComponent(ctx, logger.ComponentService).Errorf("Failed to create user: %v", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return createdUser, nil
+}
+
+// UpdateProfile updates the editable profile fields of a user
+func (s userService) UpdateProfile(ctx context.Context, uuid, name, phone string) (*model.User, error) {
+	if err := s.authorize(ctx, PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != user.Name {
+		if err := s.ensureAvailable(ctx, name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := user.UpdateProfile(name, phone); err != nil {
+		return nil, fmt.Errorf("invalid update data: %w", err)
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByUUID retrieves a user by UUID
+func (s userService) GetByUUID(ctx context.Context, uuid string) (*model.User, error) {
+	if err := s.authorize(ctx, PermUsersRead); err != nil {
+		return nil, err
+	}
+	return s.getByUUID(ctx, uuid)
+}
+
+// GetByEmail retrieves a user by email
+func (s userService) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	if err := s.authorize(ctx, PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s userService) getByUUID(ctx context.Context, uuid string) (*model.User, error) {
+	user, err := s.userRepo.GetByUUID(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// ensureAvailable checks that no other account already uses the name or email; empty values are skipped
+func (s userService) ensureAvailable(ctx context.Context, name, email string) error {
+	if name != "" {
+		if _, err := s.userRepo.GetByName(ctx, name); err == nil {
+			return model.ErrUserNameTaken
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check user name: %w", err)
+		}
+	}
+
+	if email != "" {
+		if _, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+			return model.ErrUserEmailTaken
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check user email: %w", err)
+		}
+	}
+
+	return nil
+}
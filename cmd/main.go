@@ -2,25 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/ntdat104/go-clean-architecture/api/middleware"
 	"github.com/ntdat104/go-clean-architecture/config"
 	"github.com/ntdat104/go-clean-architecture/infra/repository"
+	"github.com/ntdat104/go-clean-architecture/pkg/container"
+	"github.com/ntdat104/go-clean-architecture/pkg/lifecycle"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
 	"go.uber.org/zap"
 
 	http2 "github.com/ntdat104/go-clean-architecture/api/http"
 )
 
-const (
-	DefaultMetricsAddr = ":9090"
-)
-
 func main() {
 	// Initialize configuration
 	config.Init("./config", "config")
@@ -31,69 +31,142 @@ func main() {
 		zap.String("service", config.GlobalConfig.App.Name),
 		zap.String("env", string(config.GlobalConfig.Env)))
 
-	// Initialize metrics collection system
-	middleware.InitializeMetrics()
-	logger.Logger.Info("Metrics collection system initialized")
+	lifecycleConfig := config.GlobalConfig.Lifecycle
+	app := lifecycle.NewManager(
+		config.GetDuration(lifecycleConfig.ShutdownTimeout),
+		config.GetDuration(lifecycleConfig.DrainDelay),
+	)
 
-	// Initializing mysql
-	db, err := repository.NewMySQLConn()
+	// Flush buffered log entries and ship them to the sinks last
+	app.Append(lifecycle.Hook{
+		Name: "logger",
+		OnStop: func(ctx context.Context) error {
+			// Syncing stdout fails on some platforms, which is not worth reporting
+			_ = logger.Logger.Sync()
+			return logger.CloseSinks()
+		},
+	})
+
+	// Initialize tracing; spans are flushed once everything producing them has stopped
+	shutdownTracing, err := tracing.Init(context.Background())
 	if err != nil {
-		logger.Logger.Fatal("Failed to initialize mysql",
-			zap.Error(err))
+		logger.Logger.Fatal("Failed to initialize tracing", zap.Error(err))
 	}
-	logger.Logger.Info("Mysql initialized successfully")
+	app.Append(lifecycle.Hook{
+		Name:   "tracing",
+		OnStop: shutdownTracing,
+	})
 
-	// Initializing redis
-	rdb, err := repository.NewRedisConn()
+	// Initialize metrics collection system
+	metricsConfig := config.GlobalConfig.MetricsServer
+	if err := metrics.Init(
+		metrics.WithHTTPBuckets(metricsConfig.HTTPBuckets),
+		metrics.WithDBBuckets(metricsConfig.DBBuckets),
+	); err != nil {
+		logger.Logger.Fatal("Failed to initialize metrics", zap.Error(err))
+	}
+	logger.Logger.Info("Metrics collection system initialized")
+	if err := metrics.Register(metrics.NewLogDroppedCollector(logger.DroppedEntries.Snapshot)); err != nil {
+		logger.Logger.Warn("Failed to register log sampling metrics", zap.Error(err))
+	}
+
+	// Open the configured storage backends, retrying while they come up
+	repoClient, err := repository.NewClientFromConfig(context.Background())
 	if err != nil {
-		logger.Logger.Fatal("Failed to initialize redis",
-			zap.Error(err))
+		logger.Logger.Fatal("Failed to initialize repositories", zap.Error(err))
 	}
-	logger.Logger.Info("Redis initialized successfully")
-
-	// Start metrics server in a separate goroutine if enabled
-	if config.GlobalConfig.MetricsServer != nil && config.GlobalConfig.MetricsServer.Enabled {
-		metricsAddr := config.GlobalConfig.MetricsServer.Addr
-		if metricsAddr == "" {
-			metricsAddr = DefaultMetricsAddr
-		}
-		go func() {
-			if err := middleware.StartMetricsServer(metricsAddr); err != nil {
-				logger.Logger.Error("Failed to start metrics server", zap.Error(err))
-			}
-		}()
-		logger.Logger.Info("Metrics server started", zap.String("address", metricsAddr))
-	} else {
-		logger.Logger.Info("Metrics server is disabled")
+	logger.Logger.Info("Repositories initialized successfully",
+		zap.Strings("backends", config.GlobalConfig.Repository.Backends))
+	app.Append(lifecycle.Hook{
+		Name: "repositories",
+		OnStop: func(ctx context.Context) error {
+			return repoClient.Close()
+		},
+	})
+	if err := repository.RegisterPoolMetrics(repoClient); err != nil {
+		logger.Logger.Warn("Failed to register connection pool metrics", zap.Error(err))
 	}
 
-	// Create context and cancel function
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Metrics server, its /ready endpoint follows the application readiness.
+	// In router mode the main router serves the metrics instead.
+	switch {
+	case !metricsConfig.Enabled:
+		logger.Logger.Info("Metrics server is disabled")
+	case metricsConfig.Mode == metrics.ModeRouter:
+		logger.Logger.Info("Metrics served by the HTTP server", zap.String("path", metricsConfig.Path))
+	default:
+		app.Append(serverHook("metrics server", metrics.NewServer(metricsConfig.Addr, metricsConfig.Path, app.Ready), app))
+	}
 
-	router := http2.NewServerRoute(db, rdb)
+	// Wire the feature modules; components they build register their own lifecycle hooks
+	deps := container.New()
+	if db := repoClient.DB(); db != nil {
+		container.Supply(deps, db)
+	}
+	if repoClient.Redis != nil {
+		container.Supply(deps, repoClient.Redis)
+	}
+	router, err := http2.NewRouter(deps, http2.DefaultModules()...)
+	if err != nil {
+		logger.Logger.Fatal("Failed to build router", zap.Error(err))
+	}
+	http2.RegisterReadiness(router, app.Ready)
+	for _, hook := range deps.Hooks() {
+		app.Append(hook)
+	}
 
+	httpServerConfig := config.GlobalConfig.HTTPServer
 	srv := &http.Server{
-		Addr:    config.GlobalConfig.HTTPServer.Addr,
-		Handler: router,
+		Addr:              httpServerConfig.Addr,
+		Handler:           router,
+		ReadTimeout:       config.GetDuration(httpServerConfig.ReadTimeout),
+		ReadHeaderTimeout: config.GetDuration(httpServerConfig.ReadHeaderTimeout),
+		WriteTimeout:      config.GetDuration(httpServerConfig.WriteTimeout),
+		IdleTimeout:       config.GetDuration(httpServerConfig.IdleTimeout),
 	}
+	// Registered last so it is the first to stop and in-flight requests drain before the pools close
+	app.Append(serverHook("http server", srv, app))
 
-	// Run server in a goroutine
-	go func() {
-		log.Printf("%v started on http://%v%v", config.GlobalConfig.App.Name, "localhost", config.GlobalConfig.HTTPServer.Addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf(config.GlobalConfig.App.Name+" failed to start: %v", err)
-		}
-	}()
+	if err := app.Start(context.Background()); err != nil {
+		logger.Logger.Error("Failed to start application", zap.Error(err))
+		os.Exit(1)
+	}
+	logger.Logger.Info(config.GlobalConfig.App.Name+" started", zap.String("address", httpServerConfig.Addr))
 
 	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := app.Wait(context.Background()); err != nil {
+		logger.Logger.Error("Application failed, shutting down", zap.Error(err))
+	}
+	logger.Logger.Info("Shutting down server...")
+	if err := app.Stop(context.Background()); err != nil {
+		// The logger may already be closed, report on stderr as well
+		fmt.Fprintf(os.Stderr, "Shutdown finished with errors: %v\n", err)
+		os.Exit(1)
 	}
+	// The logger sinks are closed by now
 	log.Println("Server exiting")
 }
+
+// serverHook binds srv when starting, so address errors fail the start, and drains it when stopping.
+// A server failing after start makes the application shut down.
+func serverHook(name string, srv *http.Server, app *lifecycle.Manager) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					app.Fail(fmt.Errorf("%s stopped: %w", name, err))
+				}
+			}()
+			logger.Logger.Info(name+" listening", zap.String("address", listener.Addr().String()))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+	}
+}
@@ -8,9 +8,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cast"
-	"github.com/spf13/viper"
 )
 
 // Constants
@@ -24,10 +22,46 @@ func (e Env) IsProd() bool {
 	return e == "prod"
 }
 
+// GlobalConfig is the configuration loaded by Init. A reload replaces it rather than modifying it;
+// code running while the configuration may reload should read Get instead.
 var GlobalConfig *Config
 var configMutex sync.RWMutex
 var lastConfigChangeTime time.Time
 
+// current holds the configuration loaded by Init and its reloads
+var current = NewHolder(nil)
+
+func init() {
+	// Keep GlobalConfig in step with the holder before other subscribers run
+	current.Subscribe(func(change Change) {
+		configMutex.Lock()
+		defer configMutex.Unlock()
+		GlobalConfig = change.New
+		lastConfigChangeTime = time.Now()
+	})
+}
+
+// Get returns a snapshot of the current configuration, falling back to GlobalConfig when
+// none was loaded by Init, as in tests. The snapshot must not be modified.
+func Get() *Config {
+	if conf := current.Get(); conf != nil {
+		return conf
+	}
+	return GlobalConfig
+}
+
+// Update validates conf and makes it the current configuration the way a reload of the
+// configuration file does, rejecting it when invalid. conf must not be modified afterwards.
+func Update(conf *Config) (Change, error) {
+	return current.Update(conf)
+}
+
+// Subscribe registers fn to be called after the configuration file is reloaded with changes.
+// Use Change.Changed to react only to the keys a component depends on.
+func Subscribe(fn func(Change)) func() {
+	return current.Subscribe(fn)
+}
+
 // GetLastConfigChangeTime returns the time when the config was last changed
 func GetLastConfigChangeTime() time.Time {
 	configMutex.RLock()
@@ -36,17 +70,30 @@ func GetLastConfigChangeTime() time.Time {
 }
 
 type Config struct {
-	Env           Env               `yaml:"env" mapstructure:"env"`
-	App           *AppConfig        `yaml:"app" mapstructure:"app"`
-	HTTPServer    *HttpServerConfig `yaml:"http_server" mapstructure:"http_server"`
-	MetricsServer *MetricsConfig    `yaml:"metrics_server" mapstructure:"metrics_server"`
-	Log           *LogConfig        `yaml:"log" mapstructure:"log"`
-	SQLite        *SQLiteConfig     `yaml:"sqlite" mapstructure:"sqlite"`
-	MySQL         *MySQLConfig      `yaml:"mysql" mapstructure:"mysql"`
-	Redis         *RedisConfig      `yaml:"redis" mapstructure:"redis"`
-	Postgre       *PostgreSQLConfig `yaml:"postgres" mapstructure:"postgres"`
-	MongoDB       *MongoDBConfig    `yaml:"mongodb" mapstructure:"mongodb"`
-	MigrationDir  string            `yaml:"migration_dir" mapstructure:"migration_dir"`
+	Env           Env                `yaml:"env" mapstructure:"env"`
+	App           *AppConfig         `yaml:"app" mapstructure:"app" validate:"required"`
+	HTTPServer    *HttpServerConfig  `yaml:"http_server" mapstructure:"http_server" validate:"required"`
+	MetricsServer *MetricsConfig     `yaml:"metrics_server" mapstructure:"metrics_server"`
+	Log           *LogConfig         `yaml:"log" mapstructure:"log" validate:"required"`
+	SQLite        *SQLiteConfig      `yaml:"sqlite" mapstructure:"sqlite"`
+	MySQL         *MySQLConfig       `yaml:"mysql" mapstructure:"mysql"`
+	Redis         *RedisConfig       `yaml:"redis" mapstructure:"redis"`
+	Postgre       *PostgreSQLConfig  `yaml:"postgres" mapstructure:"postgres"`
+	MongoDB       *MongoDBConfig     `yaml:"mongodb" mapstructure:"mongodb"`
+	Authz         *AuthzConfig       `yaml:"authz" mapstructure:"authz"`
+	RateLimit     *RateLimitConfig   `yaml:"rate_limit" mapstructure:"rate_limit"`
+	Idempotency   *IdempotencyConfig `yaml:"idempotency" mapstructure:"idempotency"`
+	Lifecycle     *LifecycleConfig   `yaml:"lifecycle" mapstructure:"lifecycle"`
+	Repository    *RepositoryConfig  `yaml:"repository" mapstructure:"repository"`
+	Tracing       *TracingConfig     `yaml:"tracing" mapstructure:"tracing"`
+	I18n          *I18nConfig        `yaml:"i18n" mapstructure:"i18n"`
+	OpenAPI       *OpenAPIConfig     `yaml:"openapi" mapstructure:"openapi"`
+	MigrationDir  string             `yaml:"migration_dir" mapstructure:"migration_dir"`
+
+	// secrets are the keys whose values were resolved from ${env:} and ${file:} references
+	secrets map[string]bool
+	// sources are the files or environment that set each key, see Settings
+	sources map[string]string
 }
 
 type AppConfig struct {
@@ -55,49 +102,158 @@ type AppConfig struct {
 	Version string `yaml:"version" mapstructure:"version"`
 }
 
+// HttpServerConfig configures the HTTP server.
+// HandlerTimeout is the default request deadline and RouteTimeouts override it per route; empty disables it.
+// MaxBodyBytes is the largest accepted request body, zero disables the limit.
+// ErrorFormat is "standard" (the default) for the StandardResponse envelope or "problem" for RFC 7807
+// application/problem+json; clients sending Accept: application/problem+json always get the latter.
 type HttpServerConfig struct {
-	Addr            string `yaml:"addr" mapstructure:"addr"`
-	Pprof           bool   `yaml:"pprof" mapstructure:"pprof"`
-	DefaultPageSize int    `yaml:"default_page_size" mapstructure:"default_page_size"`
-	MaxPageSize     int    `yaml:"max_page_size" mapstructure:"max_page_size"`
-	ReadTimeout     string `yaml:"read_timeout" mapstructure:"read_timeout"`
-	WriteTimeout    string `yaml:"write_timeout" mapstructure:"write_timeout"`
+	Addr              string               `yaml:"addr" mapstructure:"addr" validate:"required,hostname_port"`
+	Pprof             bool                 `yaml:"pprof" mapstructure:"pprof"`
+	DefaultPageSize   int                  `yaml:"default_page_size" mapstructure:"default_page_size" validate:"min=1"`
+	MaxPageSize       int                  `yaml:"max_page_size" mapstructure:"max_page_size" validate:"min=1"`
+	ReadTimeout       string               `yaml:"read_timeout" mapstructure:"read_timeout" validate:"omitempty,duration"`
+	WriteTimeout      string               `yaml:"write_timeout" mapstructure:"write_timeout" validate:"omitempty,duration"`
+	IdleTimeout       string               `yaml:"idle_timeout" mapstructure:"idle_timeout" validate:"omitempty,duration"`
+	ReadHeaderTimeout string               `yaml:"read_header_timeout" mapstructure:"read_header_timeout" validate:"omitempty,duration"`
+	HandlerTimeout    string               `yaml:"handler_timeout" mapstructure:"handler_timeout" validate:"omitempty,duration"`
+	RouteTimeouts     []RouteTimeoutConfig `yaml:"route_timeouts" mapstructure:"route_timeouts" validate:"dive"`
+	MaxBodyBytes      int64                `yaml:"max_body_bytes" mapstructure:"max_body_bytes" validate:"min=0"`
+	ErrorFormat       string               `yaml:"error_format" mapstructure:"error_format" validate:"omitempty,oneof=standard problem"`
+	CORS              *CORSConfig          `yaml:"cors" mapstructure:"cors"`
+}
+
+// RouteTimeoutConfig is the handler deadline of a route, identified by method and gin path pattern
+type RouteTimeoutConfig struct {
+	Method  string `yaml:"method" mapstructure:"method" validate:"required"`
+	Path    string `yaml:"path" mapstructure:"path" validate:"required,startswith=/"`
+	Timeout string `yaml:"timeout" mapstructure:"timeout" validate:"required,duration"`
 }
 
+// CORSConfig is the cross-origin policy of the HTTP server.
+// Origins may be exact, "*" or a subdomain pattern such as "https://*.example.com".
+type CORSConfig struct {
+	AllowOrigins     []string `yaml:"allow_origins" mapstructure:"allow_origins"`
+	AllowMethods     []string `yaml:"allow_methods" mapstructure:"allow_methods"`
+	AllowHeaders     []string `yaml:"allow_headers" mapstructure:"allow_headers"`
+	ExposeHeaders    []string `yaml:"expose_headers" mapstructure:"expose_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials" mapstructure:"allow_credentials"`
+	MaxAge           string   `yaml:"max_age" mapstructure:"max_age" validate:"omitempty,duration"`
+}
+
+// MetricsConfig controls metrics exposition. Mode "server" (the default) serves Path on a dedicated
+// listener at Addr, "router" serves it on the main HTTP router. Empty buckets keep the Prometheus defaults.
 type MetricsConfig struct {
-	Addr    string `yaml:"addr" mapstructure:"addr"`
-	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
-	Path    string `yaml:"path" mapstructure:"path"`
+	Addr        string    `yaml:"addr" mapstructure:"addr" validate:"omitempty,hostname_port"`
+	Enabled     bool      `yaml:"enabled" mapstructure:"enabled"`
+	Path        string    `yaml:"path" mapstructure:"path" validate:"omitempty,startswith=/"`
+	Mode        string    `yaml:"mode" mapstructure:"mode" validate:"omitempty,oneof=server router"`
+	HTTPBuckets []float64 `yaml:"http_buckets" mapstructure:"http_buckets" validate:"dive,gt=0"`
+	DBBuckets   []float64 `yaml:"db_buckets" mapstructure:"db_buckets" validate:"dive,gt=0"`
 }
 
 type LogConfig struct {
-	SavePath         string `yaml:"save_path" mapstructure:"save_path"`
-	FileName         string `yaml:"file_name" mapstructure:"file_name"`
-	MaxSize          int    `yaml:"max_size" mapstructure:"max_size"`
-	MaxAge           int    `yaml:"max_age" mapstructure:"max_age"`
-	LocalTime        bool   `yaml:"local_time" mapstructure:"local_time"`
-	Compress         bool   `yaml:"compress" mapstructure:"compress"`
-	Level            string `yaml:"level" mapstructure:"level"`
-	EnableConsole    bool   `yaml:"enable_console" mapstructure:"enable_console"`
-	EnableColor      bool   `yaml:"enable_color" mapstructure:"enable_color"`
-	EnableCaller     bool   `yaml:"enable_caller" mapstructure:"enable_caller"`
-	EnableStacktrace bool   `yaml:"enable_stacktrace" mapstructure:"enable_stacktrace"`
+	SavePath         string           `yaml:"save_path" mapstructure:"save_path"`
+	FileName         string           `yaml:"file_name" mapstructure:"file_name"`
+	MaxSize          int              `yaml:"max_size" mapstructure:"max_size" validate:"min=0"`
+	MaxAge           int              `yaml:"max_age" mapstructure:"max_age" validate:"min=0"`
+	LocalTime        bool             `yaml:"local_time" mapstructure:"local_time"`
+	Compress         bool             `yaml:"compress" mapstructure:"compress"`
+	Level            string           `yaml:"level" mapstructure:"level" validate:"omitempty,oneof=debug info warn warning error dpanic panic fatal"`
+	EnableConsole    bool             `yaml:"enable_console" mapstructure:"enable_console"`
+	EnableColor      bool             `yaml:"enable_color" mapstructure:"enable_color"`
+	EnableCaller     bool             `yaml:"enable_caller" mapstructure:"enable_caller"`
+	EnableStacktrace bool             `yaml:"enable_stacktrace" mapstructure:"enable_stacktrace"`
+	Redaction        *RedactionConfig `yaml:"redaction" mapstructure:"redaction"`
+	Sampling         *SamplingConfig  `yaml:"sampling" mapstructure:"sampling"`
+	Sinks            *LogSinksConfig  `yaml:"sinks" mapstructure:"sinks"`
+}
+
+// RedactionConfig controls which headers, body fields and content types may appear in logs.
+// Deny listed headers are always masked; allow listed headers are logged under "headers".
+// Field paths are dotted patterns matched against the end of a key path, e.g. "password" or "*.token".
+type RedactionConfig struct {
+	Mask           string   `yaml:"mask" mapstructure:"mask"`
+	DenyHeaders    []string `yaml:"deny_headers" mapstructure:"deny_headers"`
+	AllowHeaders   []string `yaml:"allow_headers" mapstructure:"allow_headers"`
+	Fields         []string `yaml:"fields" mapstructure:"fields"`
+	MaxBodySize    int      `yaml:"max_body_size" mapstructure:"max_body_size" validate:"min=0"`
+	ContentTypes   []string `yaml:"content_types" mapstructure:"content_types"`
+	BodySampleRate float64  `yaml:"body_sample_rate" mapstructure:"body_sample_rate" validate:"min=0,max=1"`
+}
+
+// SamplingConfig limits how often one message is logged. Within every Tick the first Initial entries
+// with the same level and message are written, then every Thereafter-th; Thereafter 0 drops the rest.
+// Levels overrides the rule per level name, e.g. keeping every error entry.
+type SamplingConfig struct {
+	Enabled    bool                    `yaml:"enabled" mapstructure:"enabled"`
+	Tick       string                  `yaml:"tick" mapstructure:"tick" validate:"omitempty,duration"`
+	Initial    int                     `yaml:"initial" mapstructure:"initial" validate:"min=0"`
+	Thereafter int                     `yaml:"thereafter" mapstructure:"thereafter" validate:"min=0"`
+	Levels     map[string]SamplingRule `yaml:"levels" mapstructure:"levels" validate:"dive,keys,oneof=debug info warn error dpanic panic fatal,endkeys"`
+}
+
+// SamplingRule overrides the sampling of one level, Disabled writes every entry of the level
+type SamplingRule struct {
+	Initial    int  `yaml:"initial" mapstructure:"initial" validate:"min=0"`
+	Thereafter int  `yaml:"thereafter" mapstructure:"thereafter" validate:"min=0"`
+	Disabled   bool `yaml:"disabled" mapstructure:"disabled"`
+}
+
+// LogSinksConfig enables destinations receiving JSON encoded entries besides the console and file
+type LogSinksConfig struct {
+	Syslog *SyslogSinkConfig `yaml:"syslog" mapstructure:"syslog"`
+	HTTP   *HTTPSinkConfig   `yaml:"http" mapstructure:"http"`
+	Ring   *RingSinkConfig   `yaml:"ring" mapstructure:"ring"`
+}
+
+// SyslogSinkConfig sends RFC 5424 messages over "udp" or "tcp" to Address.
+// Facility is the syslog facility code, e.g. 16 for local0. Entries are sent in the background
+// and dropped while the server is unreachable, so logging never waits for it.
+type SyslogSinkConfig struct {
+	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`
+	Network  string `yaml:"network" mapstructure:"network" validate:"omitempty,oneof=udp tcp"`
+	Address  string `yaml:"address" mapstructure:"address" validate:"required_if=Enabled true,omitempty,hostname_port"`
+	AppName  string `yaml:"app_name" mapstructure:"app_name"`
+	Facility int    `yaml:"facility" mapstructure:"facility" validate:"min=0,max=23"`
+}
+
+// HTTPSinkConfig posts batches of entries as a JSON array to URL. Up to QueueSize entries wait to be sent;
+// once full, logging blocks for at most EnqueueTimeout before the entry is dropped.
+// Failed batches are retried MaxRetries times with an exponential backoff starting at RetryBackoff.
+type HTTPSinkConfig struct {
+	Enabled        bool              `yaml:"enabled" mapstructure:"enabled"`
+	URL            string            `yaml:"url" mapstructure:"url" validate:"required_if=Enabled true,omitempty,http_url"`
+	Headers        map[string]string `yaml:"headers" mapstructure:"headers" secret:"true"`
+	BatchSize      int               `yaml:"batch_size" mapstructure:"batch_size" validate:"min=0"`
+	FlushInterval  string            `yaml:"flush_interval" mapstructure:"flush_interval" validate:"omitempty,duration"`
+	QueueSize      int               `yaml:"queue_size" mapstructure:"queue_size" validate:"min=0"`
+	EnqueueTimeout string            `yaml:"enqueue_timeout" mapstructure:"enqueue_timeout" validate:"omitempty,duration"`
+	MaxRetries     int               `yaml:"max_retries" mapstructure:"max_retries" validate:"min=0"`
+	RetryBackoff   string            `yaml:"retry_backoff" mapstructure:"retry_backoff" validate:"omitempty,duration"`
+	Timeout        string            `yaml:"timeout" mapstructure:"timeout" validate:"omitempty,duration"`
+}
+
+// RingSinkConfig keeps the last Size entries in memory, served by the admin logs endpoint
+type RingSinkConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	Size    int  `yaml:"size" mapstructure:"size" validate:"min=0"`
 }
 
 type SQLiteConfig struct {
-	Dsn string `yaml:"dsn" mapstructure:"dsn"`
+	Dsn string `yaml:"dsn" mapstructure:"dsn" validate:"required"`
 }
 
 type MySQLConfig struct {
 	User         string `yaml:"user" mapstructure:"user"`
-	Password     string `yaml:"password" mapstructure:"password"`
-	Host         string `yaml:"host" mapstructure:"host"`
-	Port         int    `yaml:"port" mapstructure:"port"`
+	Password     string `yaml:"password" mapstructure:"password" secret:"true"`
+	Host         string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port         int    `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
 	Database     string `yaml:"database" mapstructure:"database"`
-	MaxIdleConns int    `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
-	MaxOpenConns int    `yaml:"max_open_conns" mapstructure:"max_open_conns"`
-	MaxLifeTime  string `yaml:"max_life_time" mapstructure:"max_life_time"`
-	MaxIdleTime  string `yaml:"max_idle_time" mapstructure:"max_idle_time"`
+	MaxIdleConns int    `yaml:"max_idle_conns" mapstructure:"max_idle_conns" validate:"min=0"`
+	MaxOpenConns int    `yaml:"max_open_conns" mapstructure:"max_open_conns" validate:"min=0"`
+	MaxLifeTime  string `yaml:"max_life_time" mapstructure:"max_life_time" validate:"omitempty,duration"`
+	MaxIdleTime  string `yaml:"max_idle_time" mapstructure:"max_idle_time" validate:"omitempty,duration"`
 	CharSet      string `yaml:"char_set" mapstructure:"char_set"`
 	ParseTime    bool   `yaml:"parse_time" mapstructure:"parse_time"`
 	TimeZone     string `yaml:"time_zone" mapstructure:"time_zone"`
@@ -105,84 +261,147 @@ type MySQLConfig struct {
 
 type PostgreSQLConfig struct {
 	User            string `yaml:"user" mapstructure:"user"`
-	Password        string `yaml:"password" mapstructure:"password"`
-	Host            string `yaml:"host" mapstructure:"host"`
-	Port            int    `yaml:"port" mapstructure:"port"`
+	Password        string `yaml:"password" mapstructure:"password" secret:"true"`
+	Host            string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port            int    `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
 	Database        string `yaml:"database" mapstructure:"database"`
-	SSLMode         string `yaml:"ssl_mode" mapstructure:"ssl_mode"`
+	SSLMode         string `yaml:"ssl_mode" mapstructure:"ssl_mode" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
 	Options         string `yaml:"options" mapstructure:"options"`
-	MaxConnections  int32  `yaml:"max_connections" mapstructure:"max_connections"`
-	MinConnections  int32  `yaml:"min_connections" mapstructure:"min_connections"`
-	MaxConnLifetime int    `yaml:"max_conn_lifetime" mapstructure:"max_conn_lifetime"`
-	IdleTimeout     int    `yaml:"idle_timeout" mapstructure:"idle_timeout"`
-	ConnectTimeout  int    `yaml:"connect_timeout" mapstructure:"connect_timeout"`
+	MaxConnections  int32  `yaml:"max_connections" mapstructure:"max_connections" validate:"min=0"`
+	MinConnections  int32  `yaml:"min_connections" mapstructure:"min_connections" validate:"min=0"`
+	MaxConnLifetime int    `yaml:"max_conn_lifetime" mapstructure:"max_conn_lifetime" validate:"min=0"`
+	IdleTimeout     int    `yaml:"idle_timeout" mapstructure:"idle_timeout" validate:"min=0"`
+	ConnectTimeout  int    `yaml:"connect_timeout" mapstructure:"connect_timeout" validate:"min=0"`
 	TimeZone        string `yaml:"time_zone" mapstructure:"time_zone"`
 }
 
 type RedisConfig struct {
-	Host         string `yaml:"host" mapstructure:"host"`
-	Port         int    `yaml:"port" mapstructure:"port"`
-	Password     string `yaml:"password" mapstructure:"password"`
-	DB           int    `yaml:"db" mapstructure:"db"`
-	PoolSize     int    `yaml:"poolSize" mapstructure:"poolSize"`
-	IdleTimeout  int    `yaml:"idleTimeout" mapstructure:"idleTimeout"`
-	MinIdleConns int    `yaml:"minIdleConns" mapstructure:"minIdleConns"`
+	Host         string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port         int    `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
+	Password     string `yaml:"password" mapstructure:"password" secret:"true"`
+	DB           int    `yaml:"db" mapstructure:"db" validate:"min=0"`
+	PoolSize     int    `yaml:"poolSize" mapstructure:"poolSize" validate:"min=0"`
+	IdleTimeout  int    `yaml:"idleTimeout" mapstructure:"idleTimeout" validate:"min=0"`
+	MinIdleConns int    `yaml:"minIdleConns" mapstructure:"minIdleConns" validate:"min=0"`
 }
 
 type MongoDBConfig struct {
-	Host        string `yaml:"host" mapstructure:"host"`
-	Port        int    `yaml:"port" mapstructure:"port"`
+	Host        string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port        int    `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
 	Database    string `yaml:"database" mapstructure:"database"`
 	User        string `yaml:"user" mapstructure:"user"`
-	Password    string `yaml:"password" mapstructure:"password"`
+	Password    string `yaml:"password" mapstructure:"password" secret:"true"`
 	AuthSource  string `yaml:"auth_source" mapstructure:"auth_source"`
 	Options     string `yaml:"options" mapstructure:"options"`
-	MinPoolSize int    `yaml:"min_pool_size" mapstructure:"min_pool_size"`
-	MaxPoolSize int    `yaml:"max_pool_size" mapstructure:"max_pool_size"`
-	IdleTimeout int    `yaml:"idle_timeout" mapstructure:"idle_timeout"`
+	MinPoolSize int    `yaml:"min_pool_size" mapstructure:"min_pool_size" validate:"min=0"`
+	MaxPoolSize int    `yaml:"max_pool_size" mapstructure:"max_pool_size" validate:"min=0"`
+	IdleTimeout int    `yaml:"idle_timeout" mapstructure:"idle_timeout" validate:"min=0"`
 }
 
-func Load(configPath string, configFile string) (*Config, error) {
-	var conf *Config
-	vip := viper.New()
-	vip.AddConfigPath(configPath)
-	vip.SetConfigName(configFile)
+// AuthzConfig controls authorization of the principal asserted by an authenticating gateway in the
+// SubjectHeader and RolesHeader. These headers are only trusted on requests from the gateway: those whose
+// peer address is one of TrustedProxies (addresses or CIDR networks) or which carry SharedSecret in
+// SecretHeader. One of them is required when enabled, other requests are anonymous. TrustedProxies are
+// also the only peers whose X-Forwarded-For is believed for the client address, enabled or not.
+type AuthzConfig struct {
+	Enabled        bool                `yaml:"enabled" mapstructure:"enabled"`
+	Source         string              `yaml:"source" mapstructure:"source" validate:"omitempty,oneof=config database"`
+	SubjectHeader  string              `yaml:"subject_header" mapstructure:"subject_header"`
+	RolesHeader    string              `yaml:"roles_header" mapstructure:"roles_header"`
+	TrustedProxies []string            `yaml:"trusted_proxies" mapstructure:"trusted_proxies" validate:"dive,cidr|ip"`
+	SecretHeader   string              `yaml:"secret_header" mapstructure:"secret_header"`
+	SharedSecret   string              `yaml:"shared_secret" mapstructure:"shared_secret" secret:"true"`
+	Roles          map[string][]string `yaml:"roles" mapstructure:"roles"`
+}
 
-	vip.SetConfigType("yaml")
-	if err := vip.ReadInConfig(); err != nil {
-		return nil, err
-	}
+type RateLimitConfig struct {
+	Enabled   bool                   `yaml:"enabled" mapstructure:"enabled"`
+	Store     string                 `yaml:"store" mapstructure:"store" validate:"omitempty,oneof=redis memory"`
+	Algorithm string                 `yaml:"algorithm" mapstructure:"algorithm" validate:"omitempty,oneof=sliding_window token_bucket"`
+	KeyBy     string                 `yaml:"key_by" mapstructure:"key_by" validate:"omitempty,oneof=ip api_key subject"`
+	Limit     int                    `yaml:"limit" mapstructure:"limit" validate:"required_if=Enabled true,min=0"`
+	Window    string                 `yaml:"window" mapstructure:"window" validate:"required_if=Enabled true,omitempty,duration"`
+	Burst     int                    `yaml:"burst" mapstructure:"burst" validate:"min=0"`
+	Routes    []RouteRateLimitConfig `yaml:"routes" mapstructure:"routes" validate:"dive"`
+}
 
-	// Enable environment variables to override config
-	vip.SetEnvPrefix("APP")
-	vip.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	vip.AutomaticEnv()
+type RouteRateLimitConfig struct {
+	Method    string `yaml:"method" mapstructure:"method" validate:"required"`
+	Path      string `yaml:"path" mapstructure:"path" validate:"required,startswith=/"`
+	Algorithm string `yaml:"algorithm" mapstructure:"algorithm" validate:"omitempty,oneof=sliding_window token_bucket"`
+	KeyBy     string `yaml:"key_by" mapstructure:"key_by" validate:"omitempty,oneof=ip api_key subject"`
+	Limit     int    `yaml:"limit" mapstructure:"limit" validate:"min=1"`
+	Window    string `yaml:"window" mapstructure:"window" validate:"required,duration"`
+	Burst     int    `yaml:"burst" mapstructure:"burst" validate:"min=0"`
+}
 
-	err := vip.Unmarshal(&conf)
+type IdempotencyConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	TTL     string `yaml:"ttl" mapstructure:"ttl" validate:"omitempty,duration"`
+	LockTTL string `yaml:"lock_ttl" mapstructure:"lock_ttl" validate:"omitempty,duration"`
+}
+
+// RepositoryConfig selects the storage backends opened at startup and how connecting is retried.
+// Backends are any of mysql, postgres, sqlite and redis.
+type RepositoryConfig struct {
+	Backends        []string `yaml:"backends" mapstructure:"backends" validate:"dive,oneof=mysql postgres sqlite redis"`
+	ConnectAttempts int      `yaml:"connect_attempts" mapstructure:"connect_attempts" validate:"min=0"`
+	RetryBackoff    string   `yaml:"retry_backoff" mapstructure:"retry_backoff" validate:"omitempty,duration"`
+	MaxRetryBackoff string   `yaml:"max_retry_backoff" mapstructure:"max_retry_backoff" validate:"omitempty,duration"`
+	Migrate         bool     `yaml:"migrate" mapstructure:"migrate"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. Exporter is otlp (OTLP over HTTP to Endpoint),
+// stdout or file (JSON spans appended to FilePath). ServiceName defaults to app.name.
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" mapstructure:"enabled"`
+	ServiceName string  `yaml:"service_name" mapstructure:"service_name"`
+	Exporter    string  `yaml:"exporter" mapstructure:"exporter" validate:"omitempty,oneof=otlp stdout file"`
+	Endpoint    string  `yaml:"endpoint" mapstructure:"endpoint" validate:"omitempty,hostname_port"`
+	Insecure    bool    `yaml:"insecure" mapstructure:"insecure"`
+	FilePath    string  `yaml:"file_path" mapstructure:"file_path"`
+	SampleRatio float64 `yaml:"sample_ratio" mapstructure:"sample_ratio" validate:"min=0,max=1"`
+}
+
+// I18nConfig controls the language of error and response messages. DefaultLocale is used when the
+// request chooses none of the locales of the catalog by the lang query parameter, cookie or Accept-Language.
+type I18nConfig struct {
+	DefaultLocale string `yaml:"default_locale" mapstructure:"default_locale"`
+}
+
+// OpenAPIConfig serves the OpenAPI document of the HTTP API at Path and the Swagger UI reading it at UIPath.
+// The page loads the swagger-ui-dist build embedded in the binary, served under UIPath; UIAssetsURL
+// optionally overrides where it loads the scripts and styles from, e.g. a self-hosted copy.
+type OpenAPIConfig struct {
+	Enabled     bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path        string `yaml:"path" mapstructure:"path" validate:"omitempty,startswith=/"`
+	UIPath      string `yaml:"ui_path" mapstructure:"ui_path" validate:"omitempty,startswith=/"`
+	UIAssetsURL string `yaml:"ui_assets_url" mapstructure:"ui_assets_url" validate:"omitempty,http_url"`
+}
+
+// LifecycleConfig controls graceful shutdown.
+// DrainDelay is how long readiness reports false before components stop, ShutdownTimeout bounds stopping them.
+type LifecycleConfig struct {
+	ShutdownTimeout string `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout" validate:"omitempty,duration"`
+	DrainDelay      string `yaml:"drain_delay" mapstructure:"drain_delay" validate:"omitempty,duration"`
+}
+
+// Load reads the configuration file named configFile in configPath, merged with the profile of its
+// environment and the local override file, and watches them for changes. See read for the precedence.
+func Load(configPath string, configFile string) (*Config, error) {
+	conf, err := read(configPath, configFile)
 	if err != nil {
 		return nil, err
 	}
-
-	// Apply environment variable overrides
-	applyEnvOverrides(conf)
+	// Reject invalid settings before anything is started with them
+	if err := Validate(conf); err != nil {
+		return nil, err
+	}
 
 	// Setup config file change monitoring
-	vip.WatchConfig()
-	vip.OnConfigChange(func(e fsnotify.Event) {
-		// Reload configuration when file changes
-		var newConf Config
-		if err := vip.Unmarshal(&newConf); err == nil {
-			// Apply environment variable overrides to the new config
-			applyEnvOverrides(&newConf)
-
-			// Update global config with new values - with mutex protection
-			configMutex.Lock()
-			*GlobalConfig = newConf
-			lastConfigChangeTime = time.Now()
-			configMutex.Unlock()
-		}
-	})
-
+	if err := watch(configPath, configFile); err != nil {
+		return nil, err
+	}
 	return conf, nil
 }
 
@@ -197,6 +416,14 @@ func applyEnvOverrides(conf *Config) {
 	applyRedisEnvOverrides(conf)
 	applyMongoDBEnvOverrides(conf)
 	applyLogEnvOverrides(conf)
+	applyAuthzEnvOverrides(conf)
+	applyRateLimitEnvOverrides(conf)
+	applyIdempotencyEnvOverrides(conf)
+	applyLifecycleEnvOverrides(conf)
+	applyRepositoryEnvOverrides(conf)
+	applyTracingEnvOverrides(conf)
+	applyI18nEnvOverrides(conf)
+	applyOpenAPIEnvOverrides(conf)
 
 	// Migration directory
 	if migrationDir := os.Getenv("APP_MIGRATION_DIR"); migrationDir != "" {
@@ -247,6 +474,53 @@ func applyHTTPServerEnvOverrides(conf *Config) {
 	if writeTimeout := os.Getenv("APP_HTTP_SERVER_WRITE_TIMEOUT"); writeTimeout != "" {
 		conf.HTTPServer.WriteTimeout = writeTimeout
 	}
+
+	if idleTimeout := os.Getenv("APP_HTTP_SERVER_IDLE_TIMEOUT"); idleTimeout != "" {
+		conf.HTTPServer.IdleTimeout = idleTimeout
+	}
+	if readHeaderTimeout := os.Getenv("APP_HTTP_SERVER_READ_HEADER_TIMEOUT"); readHeaderTimeout != "" {
+		conf.HTTPServer.ReadHeaderTimeout = readHeaderTimeout
+	}
+	if handlerTimeout := os.Getenv("APP_HTTP_SERVER_HANDLER_TIMEOUT"); handlerTimeout != "" {
+		conf.HTTPServer.HandlerTimeout = handlerTimeout
+	}
+	if maxBodyBytes := os.Getenv("APP_HTTP_SERVER_MAX_BODY_BYTES"); maxBodyBytes != "" {
+		if val, err := strconv.ParseInt(maxBodyBytes, 10, 64); err == nil {
+			conf.HTTPServer.MaxBodyBytes = val
+		}
+	}
+	if errorFormat := os.Getenv("APP_HTTP_SERVER_ERROR_FORMAT"); errorFormat != "" {
+		conf.HTTPServer.ErrorFormat = errorFormat
+	}
+
+	applyCORSEnvOverrides(conf)
+}
+
+// applyCORSEnvOverrides applies CORS related environment variables, lists are comma separated
+func applyCORSEnvOverrides(conf *Config) {
+	if conf.HTTPServer.CORS == nil {
+		conf.HTTPServer.CORS = &CORSConfig{}
+	}
+	cors := conf.HTTPServer.CORS
+
+	if origins := os.Getenv("APP_HTTP_SERVER_CORS_ALLOW_ORIGINS"); origins != "" {
+		cors.AllowOrigins = splitList(origins)
+	}
+	if methods := os.Getenv("APP_HTTP_SERVER_CORS_ALLOW_METHODS"); methods != "" {
+		cors.AllowMethods = splitList(methods)
+	}
+	if headers := os.Getenv("APP_HTTP_SERVER_CORS_ALLOW_HEADERS"); headers != "" {
+		cors.AllowHeaders = splitList(headers)
+	}
+	if exposeHeaders := os.Getenv("APP_HTTP_SERVER_CORS_EXPOSE_HEADERS"); exposeHeaders != "" {
+		cors.ExposeHeaders = splitList(exposeHeaders)
+	}
+	if credentials := os.Getenv("APP_HTTP_SERVER_CORS_ALLOW_CREDENTIALS"); credentials != "" {
+		cors.AllowCredentials = credentials == TrueStr
+	}
+	if maxAge := os.Getenv("APP_HTTP_SERVER_CORS_MAX_AGE"); maxAge != "" {
+		cors.MaxAge = maxAge
+	}
 }
 
 // applyMetricsServerEnvOverrides applies metrics server related environment variables
@@ -257,6 +531,7 @@ func applyMetricsServerEnvOverrides(conf *Config) {
 			Addr:    ":9090",
 			Enabled: true,
 			Path:    "/metrics",
+			Mode:    "server",
 		}
 	}
 
@@ -269,10 +544,27 @@ func applyMetricsServerEnvOverrides(conf *Config) {
 	if path := os.Getenv("APP_METRICS_SERVER_PATH"); path != "" {
 		conf.MetricsServer.Path = path
 	}
+	if mode := os.Getenv("APP_METRICS_SERVER_MODE"); mode != "" {
+		conf.MetricsServer.Mode = mode
+	}
+	if buckets := os.Getenv("APP_METRICS_SERVER_HTTP_BUCKETS"); buckets != "" {
+		if val, err := splitFloats(buckets); err == nil {
+			conf.MetricsServer.HTTPBuckets = val
+		}
+	}
+	if buckets := os.Getenv("APP_METRICS_SERVER_DB_BUCKETS"); buckets != "" {
+		if val, err := splitFloats(buckets); err == nil {
+			conf.MetricsServer.DBBuckets = val
+		}
+	}
 }
 
 // applyMySQLEnvOverrides applies MySQL related environment variables
 func applyMySQLEnvOverrides(conf *Config) {
+	if conf.MySQL == nil {
+		return
+	}
+
 	if host := os.Getenv("APP_MYSQL_HOST"); host != "" {
 		conf.MySQL.Host = host
 	}
@@ -319,6 +611,10 @@ func applyMySQLEnvOverrides(conf *Config) {
 
 // applyPostgresEnvOverrides applies PostgreSQL related environment variables
 func applyPostgresEnvOverrides(conf *Config) {
+	if conf.Postgre == nil {
+		return
+	}
+
 	if host := os.Getenv("APP_POSTGRES_HOST"); host != "" {
 		conf.Postgre.Host = host
 	}
@@ -374,6 +670,10 @@ func applyPostgresEnvOverrides(conf *Config) {
 
 // applyRedisEnvOverrides applies Redis related environment variables
 func applyRedisEnvOverrides(conf *Config) {
+	if conf.Redis == nil {
+		return
+	}
+
 	if host := os.Getenv("APP_REDIS_HOST"); host != "" {
 		conf.Redis.Host = host
 	}
@@ -492,21 +792,337 @@ func applyLogEnvOverrides(conf *Config) {
 	if enableStacktrace := os.Getenv("APP_LOG_ENABLE_STACKTRACE"); enableStacktrace != "" {
 		conf.Log.EnableStacktrace = enableStacktrace == TrueStr
 	}
+
+	applyRedactionEnvOverrides(conf)
+	applySamplingEnvOverrides(conf)
+	applyLogSinksEnvOverrides(conf)
+}
+
+// applyRedactionEnvOverrides applies log redaction related environment variables, lists are comma separated
+func applyRedactionEnvOverrides(conf *Config) {
+	if conf.Log.Redaction == nil {
+		conf.Log.Redaction = &RedactionConfig{}
+	}
+	redaction := conf.Log.Redaction
+
+	if mask := os.Getenv("APP_LOG_REDACTION_MASK"); mask != "" {
+		redaction.Mask = mask
+	}
+	if denyHeaders := os.Getenv("APP_LOG_REDACTION_DENY_HEADERS"); denyHeaders != "" {
+		redaction.DenyHeaders = splitList(denyHeaders)
+	}
+	if allowHeaders := os.Getenv("APP_LOG_REDACTION_ALLOW_HEADERS"); allowHeaders != "" {
+		redaction.AllowHeaders = splitList(allowHeaders)
+	}
+	if fields := os.Getenv("APP_LOG_REDACTION_FIELDS"); fields != "" {
+		redaction.Fields = splitList(fields)
+	}
+	if maxBodySize := os.Getenv("APP_LOG_REDACTION_MAX_BODY_SIZE"); maxBodySize != "" {
+		if val, err := strconv.Atoi(maxBodySize); err == nil {
+			redaction.MaxBodySize = val
+		}
+	}
+	if contentTypes := os.Getenv("APP_LOG_REDACTION_CONTENT_TYPES"); contentTypes != "" {
+		redaction.ContentTypes = splitList(contentTypes)
+	}
+	if sampleRate := os.Getenv("APP_LOG_REDACTION_BODY_SAMPLE_RATE"); sampleRate != "" {
+		if val, err := strconv.ParseFloat(sampleRate, 64); err == nil {
+			redaction.BodySampleRate = val
+		}
+	}
+}
+
+// applySamplingEnvOverrides applies log sampling related environment variables
+func applySamplingEnvOverrides(conf *Config) {
+	if conf.Log.Sampling == nil {
+		conf.Log.Sampling = &SamplingConfig{}
+	}
+	sampling := conf.Log.Sampling
+
+	if enabled := os.Getenv("APP_LOG_SAMPLING_ENABLED"); enabled != "" {
+		sampling.Enabled = enabled == TrueStr
+	}
+	if tick := os.Getenv("APP_LOG_SAMPLING_TICK"); tick != "" {
+		sampling.Tick = tick
+	}
+	if initial := os.Getenv("APP_LOG_SAMPLING_INITIAL"); initial != "" {
+		if val, err := strconv.Atoi(initial); err == nil {
+			sampling.Initial = val
+		}
+	}
+	if thereafter := os.Getenv("APP_LOG_SAMPLING_THEREAFTER"); thereafter != "" {
+		if val, err := strconv.Atoi(thereafter); err == nil {
+			sampling.Thereafter = val
+		}
+	}
+}
+
+// applyLogSinksEnvOverrides applies log sink related environment variables
+func applyLogSinksEnvOverrides(conf *Config) {
+	if conf.Log.Sinks == nil {
+		conf.Log.Sinks = &LogSinksConfig{}
+	}
+	sinks := conf.Log.Sinks
+	if sinks.Syslog == nil {
+		sinks.Syslog = &SyslogSinkConfig{}
+	}
+	if sinks.HTTP == nil {
+		sinks.HTTP = &HTTPSinkConfig{}
+	}
+	if sinks.Ring == nil {
+		sinks.Ring = &RingSinkConfig{}
+	}
+
+	if enabled := os.Getenv("APP_LOG_SYSLOG_ENABLED"); enabled != "" {
+		sinks.Syslog.Enabled = enabled == TrueStr
+	}
+	if network := os.Getenv("APP_LOG_SYSLOG_NETWORK"); network != "" {
+		sinks.Syslog.Network = network
+	}
+	if address := os.Getenv("APP_LOG_SYSLOG_ADDRESS"); address != "" {
+		sinks.Syslog.Address = address
+	}
+	if enabled := os.Getenv("APP_LOG_HTTP_ENABLED"); enabled != "" {
+		sinks.HTTP.Enabled = enabled == TrueStr
+	}
+	if url := os.Getenv("APP_LOG_HTTP_URL"); url != "" {
+		sinks.HTTP.URL = url
+	}
+	if enabled := os.Getenv("APP_LOG_RING_ENABLED"); enabled != "" {
+		sinks.Ring.Enabled = enabled == TrueStr
+	}
+	if size := os.Getenv("APP_LOG_RING_SIZE"); size != "" {
+		if val, err := strconv.Atoi(size); err == nil {
+			sinks.Ring.Size = val
+		}
+	}
+}
+
+// applyAuthzEnvOverrides applies authorization related environment variables
+func applyAuthzEnvOverrides(conf *Config) {
+	if conf.Authz == nil {
+		return
+	}
+
+	if enabled := os.Getenv("APP_AUTHZ_ENABLED"); enabled != "" {
+		conf.Authz.Enabled = enabled == TrueStr
+	}
+	if source := os.Getenv("APP_AUTHZ_SOURCE"); source != "" {
+		conf.Authz.Source = source
+	}
+	if subjectHeader := os.Getenv("APP_AUTHZ_SUBJECT_HEADER"); subjectHeader != "" {
+		conf.Authz.SubjectHeader = subjectHeader
+	}
+	if rolesHeader := os.Getenv("APP_AUTHZ_ROLES_HEADER"); rolesHeader != "" {
+		conf.Authz.RolesHeader = rolesHeader
+	}
+	if proxies := os.Getenv("APP_AUTHZ_TRUSTED_PROXIES"); proxies != "" {
+		conf.Authz.TrustedProxies = splitList(proxies)
+	}
+	if secretHeader := os.Getenv("APP_AUTHZ_SECRET_HEADER"); secretHeader != "" {
+		conf.Authz.SecretHeader = secretHeader
+	}
+	if sharedSecret := os.Getenv("APP_AUTHZ_SHARED_SECRET"); sharedSecret != "" {
+		conf.Authz.SharedSecret = sharedSecret
+	}
+}
+
+// applyRateLimitEnvOverrides applies rate limiting related environment variables
+func applyRateLimitEnvOverrides(conf *Config) {
+	if conf.RateLimit == nil {
+		return
+	}
+
+	if enabled := os.Getenv("APP_RATE_LIMIT_ENABLED"); enabled != "" {
+		conf.RateLimit.Enabled = enabled == TrueStr
+	}
+	if store := os.Getenv("APP_RATE_LIMIT_STORE"); store != "" {
+		conf.RateLimit.Store = store
+	}
+	if algorithm := os.Getenv("APP_RATE_LIMIT_ALGORITHM"); algorithm != "" {
+		conf.RateLimit.Algorithm = algorithm
+	}
+	if keyBy := os.Getenv("APP_RATE_LIMIT_KEY_BY"); keyBy != "" {
+		conf.RateLimit.KeyBy = keyBy
+	}
+	if limit := os.Getenv("APP_RATE_LIMIT_LIMIT"); limit != "" {
+		if val, err := strconv.Atoi(limit); err == nil {
+			conf.RateLimit.Limit = val
+		}
+	}
+	if window := os.Getenv("APP_RATE_LIMIT_WINDOW"); window != "" {
+		conf.RateLimit.Window = window
+	}
+	if burst := os.Getenv("APP_RATE_LIMIT_BURST"); burst != "" {
+		if val, err := strconv.Atoi(burst); err == nil {
+			conf.RateLimit.Burst = val
+		}
+	}
+}
+
+// applyIdempotencyEnvOverrides applies idempotency related environment variables
+func applyIdempotencyEnvOverrides(conf *Config) {
+	if conf.Idempotency == nil {
+		return
+	}
+
+	if enabled := os.Getenv("APP_IDEMPOTENCY_ENABLED"); enabled != "" {
+		conf.Idempotency.Enabled = enabled == TrueStr
+	}
+	if ttl := os.Getenv("APP_IDEMPOTENCY_TTL"); ttl != "" {
+		conf.Idempotency.TTL = ttl
+	}
+	if lockTTL := os.Getenv("APP_IDEMPOTENCY_LOCK_TTL"); lockTTL != "" {
+		conf.Idempotency.LockTTL = lockTTL
+	}
+}
+
+// applyLifecycleEnvOverrides applies graceful shutdown related environment variables
+func applyLifecycleEnvOverrides(conf *Config) {
+	if conf.Lifecycle == nil {
+		conf.Lifecycle = &LifecycleConfig{}
+	}
+
+	if shutdownTimeout := os.Getenv("APP_LIFECYCLE_SHUTDOWN_TIMEOUT"); shutdownTimeout != "" {
+		conf.Lifecycle.ShutdownTimeout = shutdownTimeout
+	}
+	if drainDelay := os.Getenv("APP_LIFECYCLE_DRAIN_DELAY"); drainDelay != "" {
+		conf.Lifecycle.DrainDelay = drainDelay
+	}
+}
+
+// applyRepositoryEnvOverrides applies storage backend related environment variables
+func applyRepositoryEnvOverrides(conf *Config) {
+	if conf.Repository == nil {
+		conf.Repository = &RepositoryConfig{}
+	}
+
+	if backends := os.Getenv("APP_REPOSITORY_BACKENDS"); backends != "" {
+		conf.Repository.Backends = splitList(backends)
+	}
+	if attempts := os.Getenv("APP_REPOSITORY_CONNECT_ATTEMPTS"); attempts != "" {
+		if val, err := strconv.Atoi(attempts); err == nil {
+			conf.Repository.ConnectAttempts = val
+		}
+	}
+	if backoff := os.Getenv("APP_REPOSITORY_RETRY_BACKOFF"); backoff != "" {
+		conf.Repository.RetryBackoff = backoff
+	}
+	if maxBackoff := os.Getenv("APP_REPOSITORY_MAX_RETRY_BACKOFF"); maxBackoff != "" {
+		conf.Repository.MaxRetryBackoff = maxBackoff
+	}
+	if migrate := os.Getenv("APP_REPOSITORY_MIGRATE"); migrate != "" {
+		conf.Repository.Migrate = migrate == TrueStr
+	}
+}
+
+// applyTracingEnvOverrides applies tracing related environment variables
+func applyTracingEnvOverrides(conf *Config) {
+	if conf.Tracing == nil {
+		conf.Tracing = &TracingConfig{}
+	}
+
+	if enabled := os.Getenv("APP_TRACING_ENABLED"); enabled != "" {
+		conf.Tracing.Enabled = enabled == TrueStr
+	}
+	if serviceName := os.Getenv("APP_TRACING_SERVICE_NAME"); serviceName != "" {
+		conf.Tracing.ServiceName = serviceName
+	}
+	if exporter := os.Getenv("APP_TRACING_EXPORTER"); exporter != "" {
+		conf.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("APP_TRACING_ENDPOINT"); endpoint != "" {
+		conf.Tracing.Endpoint = endpoint
+	}
+	if insecure := os.Getenv("APP_TRACING_INSECURE"); insecure != "" {
+		conf.Tracing.Insecure = insecure == TrueStr
+	}
+	if filePath := os.Getenv("APP_TRACING_FILE_PATH"); filePath != "" {
+		conf.Tracing.FilePath = filePath
+	}
+	if ratio := os.Getenv("APP_TRACING_SAMPLE_RATIO"); ratio != "" {
+		if val, err := strconv.ParseFloat(ratio, 64); err == nil {
+			conf.Tracing.SampleRatio = val
+		}
+	}
+}
+
+// applyI18nEnvOverrides applies i18n related environment variables
+func applyI18nEnvOverrides(conf *Config) {
+	if defaultLocale := os.Getenv("APP_I18N_DEFAULT_LOCALE"); defaultLocale != "" {
+		if conf.I18n == nil {
+			conf.I18n = &I18nConfig{}
+		}
+		conf.I18n.DefaultLocale = defaultLocale
+	}
+}
+
+// applyOpenAPIEnvOverrides applies OpenAPI related environment variables
+func applyOpenAPIEnvOverrides(conf *Config) {
+	if conf.OpenAPI == nil {
+		conf.OpenAPI = &OpenAPIConfig{}
+	}
+
+	if enabled := os.Getenv("APP_OPENAPI_ENABLED"); enabled != "" {
+		conf.OpenAPI.Enabled = enabled == TrueStr
+	}
+	if path := os.Getenv("APP_OPENAPI_PATH"); path != "" {
+		conf.OpenAPI.Path = path
+	}
+	if uiPath := os.Getenv("APP_OPENAPI_UI_PATH"); uiPath != "" {
+		conf.OpenAPI.UIPath = uiPath
+	}
+	if assetsURL := os.Getenv("APP_OPENAPI_UI_ASSETS_URL"); assetsURL != "" {
+		conf.OpenAPI.UIAssetsURL = assetsURL
+	}
 }
 
 func Init(path, file string) {
 	configPath := flag.String("config-path", path, "path to configuration path")
 	configFile := flag.String("config-file", file, "name of configuration file (without extension)")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with the source of each value and exit")
 	flag.Parse()
 
 	conf, err := Load(*configPath, *configFile)
 	if err != nil {
 		panic("Load config fail : " + err.Error())
 	}
+	if *printConfig {
+		if err := conf.Dump(os.Stdout); err != nil {
+			panic("Print config fail : " + err.Error())
+		}
+		os.Exit(0)
+	}
 	GlobalConfig = conf
+	current.current.Store(conf)
 }
 
 // GetDuration converts a duration string to time.Duration
 func GetDuration(durationStr string) time.Duration {
 	return cast.ToDuration(durationStr)
 }
+
+// splitList splits a comma separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitFloats parses a comma-separated list of numbers
+func splitFloats(value string) ([]float64, error) {
+	items := splitList(value)
+	floats := make([]float64, 0, len(items))
+	for _, item := range items {
+		f, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, err
+		}
+		floats = append(floats, f)
+	}
+	return floats, nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Change describes a configuration update delivered to subscribers
+type Change struct {
+	Old *Config
+	New *Config
+	// Keys are the sorted dotted keys whose values changed, e.g. "http_server.cors.allow_origins"
+	Keys []string
+}
+
+// Changed reports whether any of the given keys, or a key below one of them, changed
+func (c Change) Changed(keys ...string) bool {
+	for _, changed := range c.Keys {
+		for _, key := range keys {
+			if changed == key || strings.HasPrefix(changed, key+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Holder keeps the current configuration. Readers get an immutable snapshot without locking;
+// updates are validated before they replace it and then announced to the subscribers.
+type Holder struct {
+	current atomic.Pointer[Config]
+	// updateMu serializes updates so subscribers see them in order
+	updateMu sync.Mutex
+
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]func(Change)
+	order       []int
+}
+
+// NewHolder creates a holder starting with conf, which is not validated
+func NewHolder(conf *Config) *Holder {
+	h := &Holder{subscribers: make(map[int]func(Change))}
+	if conf != nil {
+		h.current.Store(conf)
+	}
+	return h
+}
+
+// Get returns the current configuration, nil before one is set. The snapshot must not be modified.
+func (h *Holder) Get() *Config {
+	return h.current.Load()
+}
+
+// Update validates conf and, when it differs from the current configuration, replaces it and calls
+// every subscriber with the change. An invalid conf is rejected and the current one kept.
+// conf must not be modified afterwards; subscribers must not call Update.
+func (h *Holder) Update(conf *Config) (Change, error) {
+	if err := Validate(conf); err != nil {
+		return Change{}, err
+	}
+
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	old := h.current.Load()
+	change := Change{Old: old, New: conf, Keys: Diff(old, conf)}
+	if len(change.Keys) == 0 {
+		return change, nil
+	}
+	h.current.Store(conf)
+
+	for _, subscriber := range h.snapshotSubscribers() {
+		subscriber(change)
+	}
+	return change, nil
+}
+
+// Subscribe registers fn to be called after every accepted update that changed a key,
+// in registration order. The returned function unregisters fn.
+func (h *Holder) Subscribe(fn func(Change)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	h.subscribers[id] = fn
+	h.order = append(h.order, id)
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[id]; ok {
+			delete(h.subscribers, id)
+			h.order = slices.DeleteFunc(h.order, func(other int) bool { return other == id })
+		}
+	}
+}
+
+// snapshotSubscribers copies the subscribers so they run without holding h.mu
+func (h *Holder) snapshotSubscribers() []func(Change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscribers := make([]func(Change), 0, len(h.order))
+	for _, id := range h.order {
+		subscribers = append(subscribers, h.subscribers[id])
+	}
+	return subscribers
+}
+
+// Diff returns the sorted dotted keys whose values differ between old and new.
+// Sections are compared field by field; lists and maps are compared as a whole.
+func Diff(old, new *Config) []string {
+	if old == nil {
+		old = &Config{}
+	}
+	if new == nil {
+		new = &Config{}
+	}
+
+	var keys []string
+	diffValues(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), "", &keys)
+	slices.Sort(keys)
+	return keys
+}
+
+// diffValues appends the keys below path whose values differ
+func diffValues(old, new reflect.Value, path string, keys *[]string) {
+	if old.Kind() == reflect.Pointer {
+		if old.IsNil() || new.IsNil() {
+			if old.IsNil() != new.IsNil() {
+				*keys = append(*keys, path)
+			}
+			return
+		}
+		old, new = old.Elem(), new.Elem()
+	}
+
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*keys = append(*keys, path)
+		}
+		return
+	}
+
+	for i := range old.NumField() {
+		if field := old.Type().Field(i); field.IsExported() {
+			diffValues(old.Field(i), new.Field(i), joinKey(path, field), keys)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	conf := &Config{}
+	applyDefaults(conf)
+	return conf
+}
+
+func TestDiff(t *testing.T) {
+	old := validConfig()
+	old.HTTPServer.CORS = &CORSConfig{AllowOrigins: []string{"https://a.example.com"}}
+	new := validConfig()
+	new.HTTPServer.CORS = &CORSConfig{AllowOrigins: []string{"https://b.example.com"}}
+	new.HTTPServer.MaxPageSize = 50
+	new.Log.Level = "warn"
+	new.Redis = &RedisConfig{Host: "127.0.0.1", Port: 6379}
+
+	assert.Equal(t, []string{
+		"http_server.cors.allow_origins",
+		"http_server.max_page_size",
+		"log.level",
+		"redis",
+	}, Diff(old, new))
+	assert.Empty(t, Diff(old, old))
+}
+
+func TestChangeChanged(t *testing.T) {
+	change := Change{Keys: []string{"http_server.cors.allow_origins", "log.level"}}
+
+	assert.True(t, change.Changed("http_server.cors"))
+	assert.True(t, change.Changed("rate_limit", "log.level"))
+	assert.False(t, change.Changed("http_server.addr"))
+	// Prefixes match whole keys only
+	assert.False(t, change.Changed("log.lev"))
+}
+
+func TestHolderUpdate(t *testing.T) {
+	holder := NewHolder(validConfig())
+
+	var changes []Change
+	unsubscribe := holder.Subscribe(func(change Change) {
+		changes = append(changes, change)
+	})
+
+	next := validConfig()
+	next.Log.Level = "error"
+	change, err := holder.Update(next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log.level"}, change.Keys)
+	assert.Same(t, next, holder.Get())
+	require.Len(t, changes, 1)
+	assert.Same(t, next, changes[0].New)
+
+	// Unchanged configurations are not announced
+	same := validConfig()
+	same.Log.Level = "error"
+	_, err = holder.Update(same)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	// Invalid configurations are rejected and the current one kept
+	invalid := validConfig()
+	invalid.HTTPServer.Addr = "nowhere"
+	_, err = holder.Update(invalid)
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Same(t, next, holder.Get())
+	assert.Len(t, changes, 1)
+
+	unsubscribe()
+	final := validConfig()
+	_, err = holder.Update(final)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestHolderSubscribersRunInOrder(t *testing.T) {
+	holder := NewHolder(validConfig())
+
+	var calls []string
+	holder.Subscribe(func(Change) { calls = append(calls, "first") })
+	holder.Subscribe(func(Change) { calls = append(calls, "second") })
+
+	next := validConfig()
+	next.App.Name = "renamed"
+	_, err := holder.Update(next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Sources of the values not read from a configuration file
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+)
+
+// LocalProfile names the optional override file merged last, e.g. config.local.yml,
+// meant for settings of one machine that are not committed
+const LocalProfile = "local"
+
+// Setting is one value of the effective configuration and where it comes from:
+// a file name, SourceEnv or SourceDefault. Secret values are masked.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// read merges the layers of the configuration, from lowest to highest precedence:
+//   - configFile, e.g. config.yml
+//   - the profile of the environment, e.g. config.prod.yml
+//   - the local override file, e.g. config.local.yml
+//   - the APP_* environment variables
+//
+// The environment is APP_ENV, or else the env of the local or base file. Lists are replaced
+// by the higher layer while sections are merged key by key. Defaults fill what is left empty
+// and the secret references are resolved last; the result is not validated.
+func read(configPath, configFile string) (*Config, error) {
+	base, err := readLayer(configPath, configFile, true)
+	if err != nil {
+		return nil, err
+	}
+	local, err := readLayer(configPath, configFile+"."+LocalProfile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []*viper.Viper{base}
+	if env := profileEnv(base, local); env != "" && env != LocalProfile {
+		profile, err := readLayer(configPath, configFile+"."+env, false)
+		if err != nil {
+			return nil, err
+		}
+		if profile != nil {
+			layers = append(layers, profile)
+		}
+	}
+	if local != nil {
+		layers = append(layers, local)
+	}
+
+	vip := viper.New()
+	// Enable environment variables to override config
+	vip.SetEnvPrefix("APP")
+	vip.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	vip.AutomaticEnv()
+	for _, layer := range layers {
+		if err := vip.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, err
+		}
+	}
+
+	conf, err := unmarshal(vip)
+	if err != nil {
+		return nil, err
+	}
+	// The keys set by the environment overrides are found by comparing with the configuration without them
+	withoutOverrides, err := unmarshal(vip)
+	if err != nil {
+		return nil, err
+	}
+	applyEnvOverrides(conf)
+	conf.sources = sourcesOf(conf, vip, layers, Diff(withoutOverrides, conf))
+
+	// Environment overrides may hold references too
+	if err := resolveSecrets(conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// readLayer reads the configuration file named name in configPath, whatever its extension.
+// A missing optional file returns nil.
+func readLayer(configPath, name string, required bool) (*viper.Viper, error) {
+	layer := viper.New()
+	layer.AddConfigPath(configPath)
+	layer.SetConfigName(name)
+	layer.SetConfigType("yaml")
+
+	err := layer.ReadInConfig()
+	if notFound := (viper.ConfigFileNotFoundError{}); errors.As(err, &notFound) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return layer, nil
+}
+
+// profileEnv returns the environment selecting the profile file
+func profileEnv(base, local *viper.Viper) string {
+	if env := os.Getenv("APP_ENV"); env != "" {
+		return env
+	}
+	if local != nil && local.IsSet("env") {
+		return local.GetString("env")
+	}
+	return base.GetString("env")
+}
+
+// unmarshal decodes the merged layers and applies the defaults
+func unmarshal(vip *viper.Viper) (*Config, error) {
+	var conf *Config
+	if err := vip.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	if conf == nil {
+		conf = &Config{}
+	}
+	applyDefaults(conf)
+	return conf, nil
+}
+
+// sourcesOf maps the keys of conf to the layer that set them last. Viper lowercases keys
+// and flattens maps, so file keys are matched to settings ignoring case and below map settings.
+func sourcesOf(conf *Config, vip *viper.Viper, layers []*viper.Viper, overridden []string) map[string]string {
+	var keys []string
+	walkSettings(reflect.ValueOf(conf).Elem(), "", false, func(key string, _ reflect.Value, _ bool) {
+		keys = append(keys, key)
+	})
+
+	sources := make(map[string]string)
+	assign := func(key, source string) {
+		key = strings.ToLower(key)
+		for _, setting := range keys {
+			lower := strings.ToLower(setting)
+			if key == lower || strings.HasPrefix(key, lower+".") || strings.HasPrefix(lower, key+".") {
+				sources[setting] = source
+			}
+		}
+	}
+
+	for _, layer := range layers {
+		name := filepath.Base(layer.ConfigFileUsed())
+		for _, key := range layer.AllKeys() {
+			assign(key, name)
+		}
+	}
+	// Environment variables bound automatically to the keys of the files, then the explicit overrides
+	for _, key := range vip.AllKeys() {
+		if _, ok := os.LookupEnv("APP_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))); ok {
+			assign(key, SourceEnv)
+		}
+	}
+	for _, key := range overridden {
+		assign(key, SourceEnv)
+	}
+	return sources
+}
+
+// Settings returns the effective configuration sorted by key, each value with its source.
+// Sections are expanded to their settings while lists and maps are single settings.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	walkSettings(reflect.ValueOf(c).Elem(), "", false, func(key string, value reflect.Value, secret bool) {
+		source := c.sources[key]
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: maskedValue(value, key, secret, c.secrets), Source: source})
+	})
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Key, b.Key) })
+	return settings
+}
+
+// Dump writes the effective configuration to w as a table of keys, sources and JSON encoded values
+func (c *Config) Dump(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "KEY\tSOURCE\tVALUE")
+	for _, setting := range c.Settings() {
+		value, err := json.Marshal(setting.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", setting.Key, setting.Source, value)
+	}
+	return table.Flush()
+}
+
+// walkSettings calls fn with every setting below path; sections left empty are skipped
+func walkSettings(v reflect.Value, path string, secret bool, fn func(key string, value reflect.Value, secret bool)) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		fn(path, v, secret)
+		return
+	}
+
+	for i := range v.NumField() {
+		if field := v.Type().Field(i); field.IsExported() {
+			walkSettings(v.Field(i), joinKey(path, field), secret || field.Tag.Get("secret") == TrueStr, fn)
+		}
+	}
+}
+
+// watch reloads the configuration when one of its files changes. The directory is watched
+// rather than the files, so a profile or local file created later is picked up too.
+func watch(configPath, configFile string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(configPath); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 && isLayerFile(event.Name, configFile) {
+					reload(configPath, configFile)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "Config watcher error: %v\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// isLayerFile reports whether path is configFile or one of its profiles, whatever the extension
+func isLayerFile(path, configFile string) bool {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return name == configFile || strings.HasPrefix(name, configFile+".")
+}
+
+// reload reads the configuration again and makes it current, keeping the running one when invalid
+func reload(configPath, configFile string) {
+	conf, err := read(configPath, configFile)
+	if err == nil {
+		_, err = Update(conf)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring config change: %v\n", err)
+	}
+}
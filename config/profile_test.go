@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func sourceOf(t *testing.T, conf *Config, key string) string {
+	t.Helper()
+	for _, setting := range conf.Settings() {
+		if setting.Key == key {
+			return setting.Source
+		}
+	}
+	t.Fatalf("setting %s not found", key)
+	return ""
+}
+
+func TestLoadMergesProfiles(t *testing.T) {
+	dir := writeConfig(t, `
+env: staging
+app:
+  name: base
+  version: v1
+http_server:
+  addr: :8080
+  max_page_size: 100
+metrics_server:
+  http_buckets: [0.1, 1, 10]
+`)
+	writeProfile(t, dir, "config.staging.yml", `
+app:
+  name: staging
+http_server:
+  max_page_size: 50
+metrics_server:
+  http_buckets: [1]
+`)
+	writeProfile(t, dir, "config.local.yml", "app:\n  name: local\n")
+	writeProfile(t, dir, "config.prod.yml", "app:\n  version: v-prod\n")
+
+	conf, err := Load(dir, "config")
+	require.NoError(t, err)
+	assert.Equal(t, "local", conf.App.Name)
+	assert.Equal(t, "v1", conf.App.Version)
+	assert.Equal(t, ":8080", conf.HTTPServer.Addr)
+	assert.Equal(t, 50, conf.HTTPServer.MaxPageSize)
+	// Lists are replaced rather than merged
+	assert.Equal(t, []float64{1}, conf.MetricsServer.HTTPBuckets)
+
+	assert.Equal(t, "config.local.yml", sourceOf(t, conf, "app.name"))
+	assert.Equal(t, "config.yml", sourceOf(t, conf, "app.version"))
+	assert.Equal(t, "config.staging.yml", sourceOf(t, conf, "http_server.max_page_size"))
+	assert.Equal(t, SourceDefault, sourceOf(t, conf, "http_server.default_page_size"))
+
+	// Environment variables take precedence over every file and select the profile
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("APP_HTTP_SERVER_ADDR", ":9000")
+	conf, err = Load(dir, "config")
+	require.NoError(t, err)
+	assert.Equal(t, Env("prod"), conf.Env)
+	assert.Equal(t, "v-prod", conf.App.Version)
+	assert.Equal(t, 100, conf.HTTPServer.MaxPageSize)
+	assert.Equal(t, ":9000", conf.HTTPServer.Addr)
+	assert.Equal(t, SourceEnv, sourceOf(t, conf, "http_server.addr"))
+	assert.Equal(t, SourceEnv, sourceOf(t, conf, "env"))
+}
+
+func TestLoadShippedProdProfile(t *testing.T) {
+	t.Setenv("APP_ENV", "prod")
+	conf, err := Load("./", "config")
+	require.NoError(t, err)
+	assert.False(t, conf.App.Debug)
+	assert.Equal(t, "info", conf.Log.Level)
+	assert.Equal(t, "config.prod.yml", sourceOf(t, conf, "log.level"))
+	assert.Equal(t, "config.yml", sourceOf(t, conf, "log.file_name"))
+}
+
+func TestConfigDump(t *testing.T) {
+	t.Setenv("TEST_DUMP_PASSWORD", "dump-secret")
+	dir := writeConfig(t, `
+mysql:
+  host: 127.0.0.1
+  port: 3306
+  password: ${env:TEST_DUMP_PASSWORD}
+`)
+
+	conf, err := Load(dir, "config")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, conf.Dump(&out))
+
+	dump := out.String()
+	assert.Regexp(t, `(?m)^KEY\s+SOURCE\s+VALUE$`, dump)
+	assert.Regexp(t, `(?m)^mysql\.host\s+config\.yml\s+"127\.0\.0\.1"$`, dump)
+	assert.Regexp(t, `(?m)^mysql\.password\s+config\.yml\s+"\*+"$`, dump)
+	assert.Regexp(t, `(?m)^http_server\.addr\s+default\s+":8080"$`, dump)
+	assert.NotContains(t, dump, "dump-secret")
+}
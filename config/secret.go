@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// SecretMask replaces secret values when the configuration is printed or logged
+const SecretMask = "******"
+
+// secretReference matches a reference resolved when the configuration is loaded:
+// ${env:NAME} is the value of an environment variable, ${file:/path} the content of a file
+// without its trailing newline, as mounted by Docker and Kubernetes secrets
+var secretReference = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// resolveSecrets replaces the references in every string of conf, whatever its section, and
+// records the keys holding resolved values so they are masked. Every unresolved reference is reported.
+func resolveSecrets(conf *Config) error {
+	conf.secrets = make(map[string]bool)
+	var problems []string
+	resolveValue(reflect.ValueOf(conf).Elem(), "", conf.secrets, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// resolveValue resolves the references below path, v must be settable
+func resolveValue(v reflect.Value, path string, secrets map[string]bool, problems *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			resolveValue(v.Elem(), path, secrets, problems)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.IsExported() {
+				resolveValue(v.Field(i), joinKey(path, field), secrets, problems)
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), secrets, problems)
+		}
+	case reflect.Map:
+		// Map values cannot be set in place, they are resolved on a copy
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			resolveValue(value, fmt.Sprintf("%s[%v]", path, key), secrets, problems)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		if !strings.Contains(v.String(), "${") {
+			return
+		}
+		resolved := secretReference.ReplaceAllStringFunc(v.String(), func(reference string) string {
+			match := secretReference.FindStringSubmatch(reference)
+			value, err := lookupSecret(match[1], match[2])
+			if err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+			}
+			return value
+		})
+		if resolved != v.String() {
+			v.SetString(resolved)
+			secrets[path] = true
+		}
+	}
+}
+
+// lookupSecret returns the value a reference of the given kind points to
+func lookupSecret(kind, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("${%s:} needs a name", kind)
+	}
+
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	default:
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("cannot read secret file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+}
+
+// isSecret reports whether the value at the dotted key was resolved from a reference
+func (c *Config) isSecret(key string) bool {
+	return c.secrets[key]
+}
+
+// String prints the configuration as JSON with the secrets masked
+func (c *Config) String() string {
+	content, err := c.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("<invalid configuration: %v>", err)
+	}
+	return string(content)
+}
+
+// GoString masks the secrets of %#v as String does
+func (c *Config) GoString() string {
+	return c.String()
+}
+
+// MarshalJSON encodes the configuration by its configuration keys, masking the values resolved
+// from references and the fields tagged secret:"true" such as passwords
+func (c *Config) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(maskedValue(reflect.ValueOf(c).Elem(), "", false, c.secrets))
+}
+
+// maskedValue converts v to plain maps and lists keyed as in the configuration file, masking
+// the non-empty strings that are secret
+func maskedValue(v reflect.Value, path string, secret bool, secrets map[string]bool) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return maskedValue(v.Elem(), path, secret, secrets)
+	case reflect.Struct:
+		fields := make(map[string]any)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := joinKey(path, field)
+			fields[key[strings.LastIndex(key, ".")+1:]] = maskedValue(v.Field(i), key, secret || field.Tag.Get("secret") == TrueStr, secrets)
+		}
+		return fields
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = maskedValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), secret, secrets)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]any, v.Len())
+		for _, key := range v.MapKeys() {
+			name := fmt.Sprint(key)
+			entries[name] = maskedValue(v.MapIndex(key), fmt.Sprintf("%s[%s]", path, name), secret, secrets)
+		}
+		return entries
+	case reflect.String:
+		if v.String() != "" && (secret || secrets[path]) {
+			return SecretMask
+		}
+		return v.String()
+	default:
+		return v.Interface()
+	}
+}
+
+// joinKey appends the configuration key of field to path
+func joinKey(path string, field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
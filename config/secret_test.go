@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadResolvesSecretReferences(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "redis_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o644))
+	t.Setenv("TEST_MYSQL_PASSWORD", "from-env")
+	t.Setenv("TEST_SINK_TOKEN", "token")
+
+	dir := writeConfig(t, fmt.Sprintf(`
+mysql:
+  host: 127.0.0.1
+  port: 3306
+  password: ${env:TEST_MYSQL_PASSWORD}
+redis:
+  host: 127.0.0.1
+  port: 6379
+  password: ${file:%s}
+log:
+  sinks:
+    http:
+      url: https://logs.example.com/${env:TEST_SINK_TOKEN}/ingest
+`, secretFile))
+
+	conf, err := Load(dir, "config")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", conf.MySQL.Password)
+	assert.Equal(t, "from-file", conf.Redis.Password)
+	assert.Equal(t, "https://logs.example.com/token/ingest", conf.Log.Sinks.HTTP.URL)
+}
+
+func TestLoadReportsUnresolvedReferences(t *testing.T) {
+	dir := writeConfig(t, `
+mysql:
+  host: 127.0.0.1
+  port: 3306
+  password: ${env:TEST_UNSET_PASSWORD}
+  user: ${file:/nonexistent/user}
+`)
+
+	_, err := Load(dir, "config")
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	require.Len(t, validationError.Problems, 2)
+	assert.Contains(t, validationError.Problems, "mysql.password: environment variable TEST_UNSET_PASSWORD is not set")
+	assert.Contains(t, validationError.Problems[0]+validationError.Problems[1], "mysql.user: cannot read secret file")
+}
+
+func TestConfigStringMasksSecrets(t *testing.T) {
+	t.Setenv("TEST_APP_NAME", "resolved-name")
+	dir := writeConfig(t, `
+app:
+  name: ${env:TEST_APP_NAME}
+mysql:
+  host: 127.0.0.1
+  port: 3306
+  password: plain-password
+log:
+  sinks:
+    http:
+      headers:
+        Authorization: Bearer sink-token
+`)
+
+	conf, err := Load(dir, "config")
+	require.NoError(t, err)
+
+	for _, printed := range []string{conf.String(), fmt.Sprintf("%v", conf), fmt.Sprintf("%+v", conf), fmt.Sprintf("%#v", conf)} {
+		assert.NotContains(t, printed, "resolved-name")
+		assert.NotContains(t, printed, "plain-password")
+		assert.NotContains(t, printed, "sink-token")
+		assert.Contains(t, printed, `"host":"127.0.0.1"`)
+		assert.Contains(t, printed, `"name":"`+SecretMask+`"`)
+		// Viper lowercases map keys
+		assert.Contains(t, printed, `"authorization":"`+SecretMask+`"`)
+	}
+	// Masking does not change the values used by the application
+	assert.Equal(t, "resolved-name", conf.App.Name)
+}
+
+func TestValidateMasksSecretValues(t *testing.T) {
+	t.Setenv("TEST_HTTP_ADDR", "secret-address")
+	_, err := Load(writeConfig(t, "http_server:\n  addr: ${env:TEST_HTTP_ADDR}\n"), "config")
+
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []string{
+		`http_server.addr must be a host:port address such as :8080, got "` + SecretMask + `"`,
+	}, validationError.Problems)
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ntdat104/go-clean-architecture/pkg/i18n"
+)
+
+// Defaults applied to settings left empty
+const (
+	DefaultHTTPAddr    = ":8080"
+	DefaultPageSize    = 10
+	DefaultMaxPageSize = 100
+	DefaultMetricsAddr = ":9090"
+	DefaultMetricsPath = "/metrics"
+	DefaultMetricsMode = "server"
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// Validate checks conf against the rules declared in the validate tags of its sections
+// and the rules spanning several sections, reporting every problem at once
+func Validate(conf *Config) error {
+	var problems []string
+
+	if err := configValidator().Struct(conf); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if !errors.As(err, &fieldErrors) {
+			return err
+		}
+		for _, fieldError := range fieldErrors {
+			problems = append(problems, describe(fieldError, conf.isSecret))
+		}
+	}
+	problems = append(problems, crossSectionProblems(conf)...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// applyDefaults creates the sections the application cannot start without and fills their empty settings
+func applyDefaults(conf *Config) {
+	if conf.App == nil {
+		conf.App = &AppConfig{}
+	}
+
+	if conf.HTTPServer == nil {
+		conf.HTTPServer = &HttpServerConfig{}
+	}
+	if conf.HTTPServer.Addr == "" {
+		conf.HTTPServer.Addr = DefaultHTTPAddr
+	}
+	if conf.HTTPServer.DefaultPageSize == 0 {
+		conf.HTTPServer.DefaultPageSize = DefaultPageSize
+	}
+	if conf.HTTPServer.MaxPageSize == 0 {
+		conf.HTTPServer.MaxPageSize = DefaultMaxPageSize
+	}
+
+	if conf.MetricsServer == nil {
+		conf.MetricsServer = &MetricsConfig{}
+	}
+	if conf.MetricsServer.Addr == "" {
+		conf.MetricsServer.Addr = DefaultMetricsAddr
+	}
+	if conf.MetricsServer.Path == "" {
+		conf.MetricsServer.Path = DefaultMetricsPath
+	}
+	if conf.MetricsServer.Mode == "" {
+		conf.MetricsServer.Mode = DefaultMetricsMode
+	}
+
+	// A missing log section keeps the defaults of the logger
+	if conf.Log == nil {
+		conf.Log = &LogConfig{
+			EnableConsole:    true,
+			EnableColor:      !conf.Env.IsProd(),
+			EnableCaller:     true,
+			EnableStacktrace: true,
+		}
+	}
+
+	if conf.Lifecycle == nil {
+		conf.Lifecycle = &LifecycleConfig{}
+	}
+	if conf.Repository == nil {
+		conf.Repository = &RepositoryConfig{}
+	}
+}
+
+// crossSectionProblems checks the rules involving several settings, which tags cannot express
+func crossSectionProblems(conf *Config) []string {
+	var problems []string
+
+	if conf.HTTPServer != nil && conf.HTTPServer.DefaultPageSize > conf.HTTPServer.MaxPageSize {
+		problems = append(problems, "http_server.default_page_size must not exceed http_server.max_page_size")
+	}
+
+	// Every backend opened at startup needs its connection settings
+	if conf.Repository != nil {
+		sections := map[string]bool{
+			"mysql":    conf.MySQL != nil,
+			"postgres": conf.Postgre != nil,
+			"sqlite":   conf.SQLite != nil,
+			"redis":    conf.Redis != nil,
+		}
+		for _, backend := range conf.Repository.Backends {
+			if present, known := sections[backend]; known && !present {
+				problems = append(problems, fmt.Sprintf("%s section is required by repository.backends", backend))
+			}
+		}
+	}
+
+	// Identity headers sent by any client would grant any role
+	if conf.Authz != nil && conf.Authz.Enabled && len(conf.Authz.TrustedProxies) == 0 && conf.Authz.SharedSecret == "" {
+		problems = append(problems, "authz.trusted_proxies or authz.shared_secret is required to trust the identity headers")
+	}
+
+	if conf.Authz != nil && conf.Authz.Enabled && conf.Authz.Source == "database" {
+		var backends []string
+		if conf.Repository != nil {
+			backends = conf.Repository.Backends
+		}
+		if !slices.ContainsFunc(backends, func(backend string) bool { return backend != "redis" }) {
+			problems = append(problems, "authz.source database requires an SQL backend in repository.backends")
+		}
+	}
+
+	if conf.Tracing != nil && conf.Tracing.Enabled && conf.Tracing.Exporter == "file" && conf.Tracing.FilePath == "" {
+		problems = append(problems, "tracing.file_path is required by the file exporter")
+	}
+
+	if conf.OpenAPI != nil && conf.OpenAPI.Path != "" && conf.OpenAPI.Path == conf.OpenAPI.UIPath {
+		problems = append(problems, "openapi.path and openapi.ui_path must differ")
+	}
+
+	// The default locale must have a catalog
+	if conf.I18n != nil && conf.I18n.DefaultLocale != "" {
+		if _, ok := i18n.Default.Match(conf.I18n.DefaultLocale); !ok {
+			problems = append(problems, fmt.Sprintf("i18n.default_locale must be one of %s, got %q",
+				strings.Join(i18n.Default.Locales(), ", "), conf.I18n.DefaultLocale))
+		}
+	}
+
+	return problems
+}
+
+// configValidator returns the validator naming fields by their configuration keys
+func configValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+		// Durations are parsed strictly, although GetDuration also accepts bare numbers of nanoseconds
+		_ = validate.RegisterValidation("duration", func(fl validator.FieldLevel) bool {
+			duration, err := time.ParseDuration(fl.Field().String())
+			return err == nil && duration >= 0
+		})
+	})
+	return validate
+}
+
+// describe turns a failed rule into a message naming the configuration key.
+// Values resolved from secret references are masked.
+func describe(fieldError validator.FieldError, isSecret func(key string) bool) string {
+	// The namespace starts with the root struct name, e.g. Config.http_server.addr
+	_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+	value := fieldError.Value()
+	if isSecret(field) {
+		value = SecretMask
+	}
+
+	switch fieldError.Tag() {
+	case "required", "required_if":
+		return field + " is required"
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s, got %v", field, fieldError.Param(), value)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s, got %v", field, fieldError.Param(), value)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s, got %v", field, fieldError.Param(), value)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s, got %q", field, strings.ReplaceAll(fieldError.Param(), " ", ", "), value)
+	case "duration":
+		return fmt.Sprintf("%s must be a duration such as 30s or 5m, got %q", field, value)
+	case "hostname_port":
+		return fmt.Sprintf("%s must be a host:port address such as :8080, got %q", field, value)
+	case "http_url":
+		return fmt.Sprintf("%s must be an http or https URL, got %q", field, value)
+	case "startswith":
+		return fmt.Sprintf("%s must start with %q, got %q", field, fieldError.Param(), value)
+	default:
+		return fmt.Sprintf("%s is invalid (%s)", field, fieldError.Tag())
+	}
+}
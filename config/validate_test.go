@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yml"), []byte(content), 0o644))
+	return dir
+}
+
+func TestLoadValidatesShippedConfig(t *testing.T) {
+	conf, err := Load("./", "config")
+	require.NoError(t, err)
+	assert.NoError(t, Validate(conf))
+}
+
+func TestLoadAppliesDefaults(t *testing.T) {
+	conf, err := Load(writeConfig(t, "env: prod\n"), "config")
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultHTTPAddr, conf.HTTPServer.Addr)
+	assert.Equal(t, DefaultPageSize, conf.HTTPServer.DefaultPageSize)
+	assert.Equal(t, DefaultMaxPageSize, conf.HTTPServer.MaxPageSize)
+	assert.Equal(t, DefaultMetricsPath, conf.MetricsServer.Path)
+	assert.Equal(t, DefaultMetricsMode, conf.MetricsServer.Mode)
+	require.NotNil(t, conf.Log)
+	assert.True(t, conf.Log.EnableConsole)
+	assert.False(t, conf.Log.EnableColor)
+	assert.NotNil(t, conf.App)
+	assert.NotNil(t, conf.Lifecycle)
+	assert.NotNil(t, conf.Repository)
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	dir := writeConfig(t, `
+http_server:
+  addr: "8080"
+  default_page_size: 50
+  max_page_size: 20
+  read_timeout: soon
+  route_timeouts:
+    - path: /ping
+      timeout: 1s
+log:
+  level: verbose
+  sampling:
+    levels:
+      loud:
+        initial: 1
+repository:
+  backends: [mysql, oracle]
+tracing:
+  enabled: true
+  exporter: file
+  sample_ratio: 2
+`)
+
+	_, err := Load(dir, "config")
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	assert.ElementsMatch(t, []string{
+		`http_server.addr must be a host:port address such as :8080, got "8080"`,
+		`http_server.read_timeout must be a duration such as 30s or 5m, got "soon"`,
+		`http_server.route_timeouts[0].method is required`,
+		`log.level must be one of debug, info, warn, warning, error, dpanic, panic, fatal, got "verbose"`,
+		`log.sampling.levels[loud] must be one of debug, info, warn, error, dpanic, panic, fatal, got "loud"`,
+		`repository.backends[1] must be one of mysql, postgres, sqlite, redis, got "oracle"`,
+		`tracing.sample_ratio must be at most 1, got 2`,
+		`http_server.default_page_size must not exceed http_server.max_page_size`,
+		`mysql section is required by repository.backends`,
+		`tracing.file_path is required by the file exporter`,
+	}, validationError.Problems)
+}
+
+func TestValidateEnabledFeatures(t *testing.T) {
+	dir := writeConfig(t, `
+repository:
+  backends: [redis]
+redis:
+  host: 127.0.0.1
+  port: 6379
+authz:
+  enabled: true
+  source: database
+rate_limit:
+  enabled: true
+log:
+  sinks:
+    http:
+      enabled: true
+      url: localhost:9880
+    syslog:
+      enabled: true
+openapi:
+  enabled: true
+  path: /docs
+  ui_path: /docs
+  ui_assets_url: unpkg.com/swagger-ui-dist
+`)
+
+	_, err := Load(dir, "config")
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	assert.ElementsMatch(t, []string{
+		"log.sinks.syslog.address is required",
+		`log.sinks.http.url must be an http or https URL, got "localhost:9880"`,
+		"rate_limit.limit is required",
+		"rate_limit.window is required",
+		"authz.source database requires an SQL backend in repository.backends",
+		"authz.trusted_proxies or authz.shared_secret is required to trust the identity headers",
+		`openapi.ui_assets_url must be an http or https URL, got "unpkg.com/swagger-ui-dist"`,
+		"openapi.path and openapi.ui_path must differ",
+	}, validationError.Problems)
+}
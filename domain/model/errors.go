@@ -64,3 +64,45 @@ func IsExampleNameTakenError(err error) bool {
 func IsExampleModifiedError(err error) bool {
 	return stderrors.Is(err, ErrExampleModified)
 }
+
+// User related domain errors
+var (
+	// ErrUserNotFound indicates the requested user was not found
+	ErrUserNotFound = errors.New(errors.ErrorTypeNotFound, "user not found")
+
+	// ErrEmptyUserName indicates an empty user name was provided
+	ErrEmptyUserName = errors.New(errors.ErrorTypeValidation, "user name cannot be empty")
+
+	// ErrEmptyUserEmail indicates an empty user email was provided
+	ErrEmptyUserEmail = errors.New(errors.ErrorTypeValidation, "user email cannot be empty")
+
+	// ErrEmptyUserPassword indicates an empty user password was provided
+	ErrEmptyUserPassword = errors.New(errors.ErrorTypeValidation, "user password cannot be empty")
+
+	// ErrInvalidUserID indicates an invalid user ID was provided
+	ErrInvalidUserID = errors.New(errors.ErrorTypeValidation, "invalid user ID")
+
+	// ErrUserNameTaken indicates a user with the given name already exists
+	ErrUserNameTaken = errors.New(errors.ErrorTypeConflict, "user name already taken")
+
+	// ErrUserEmailTaken indicates an account with the given email already exists
+	ErrUserEmailTaken = errors.New(errors.ErrorTypeConflict, "user email already taken")
+)
+
+// IsUserNotFoundError checks if the error indicates a user not found condition
+func IsUserNotFoundError(err error) bool {
+	return stderrors.Is(err, ErrUserNotFound) || errors.IsNotFoundError(err)
+}
+
+// IsUserNameTakenError checks if the error indicates a user name conflict.
+// AppError.Is matches on type only, so the sentinel is compared by identity.
+func IsUserNameTakenError(err error) bool {
+	var appErr *errors.AppError
+	return stderrors.As(err, &appErr) && appErr == ErrUserNameTaken
+}
+
+// IsUserEmailTakenError checks if the error indicates an email conflict
+func IsUserEmailTakenError(err error) bool {
+	var appErr *errors.AppError
+	return stderrors.As(err, &appErr) && appErr == ErrUserEmailTaken
+}
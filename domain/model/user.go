@@ -0,0 +1,75 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/uuid"
+)
+
+// User represents a registered user account
+type User struct {
+	Id           int       `json:"id" db:"id"`
+	Uuid         string    `json:"uuid" db:"uuid"`
+	Name         string    `json:"name" db:"name"`
+	Email        string    `json:"email" db:"email"`
+	Phone        string    `json:"phone" db:"phone"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (u User) TableName() string { return "users" }
+
+// NewUser creates a new User entity with validation
+func NewUser(name, email, phone, passwordHash string) (*User, error) {
+	if name == "" {
+		return nil, ErrEmptyUserName
+	}
+	if email == "" {
+		return nil, ErrEmptyUserEmail
+	}
+	if passwordHash == "" {
+		return nil, ErrEmptyUserPassword
+	}
+	user := &User{
+		Uuid:         uuid.NewGoogleUUID(),
+		Name:         name,
+		Email:        NormalizeEmail(email),
+		Phone:        phone,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	return user, nil
+}
+
+// Validate ensures the User entity meets domain rules
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return ErrEmptyUserName
+	}
+	if u.Email == "" {
+		return ErrEmptyUserEmail
+	}
+	if u.Id < 0 {
+		return ErrInvalidUserID
+	}
+	return nil
+}
+
+// UpdateProfile changes the editable profile fields of the User entity
+func (u *User) UpdateProfile(name, phone string) error {
+	if name == "" {
+		return ErrEmptyUserName
+	}
+	u.Name = name
+	u.Phone = phone
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// NormalizeEmail lowercases and trims an email address so lookups are case insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_TableName(t *testing.T) {
+	user := User{}
+	assert.Equal(t, "users", user.TableName())
+}
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		inName   string
+		inEmail  string
+		inHash   string
+		wantErr  bool
+		errType  error
+		outEmail string
+	}{
+		{
+			name:     "should create a valid user",
+			inName:   "alice",
+			inEmail:  " Alice@Example.com ",
+			inHash:   "hash",
+			wantErr:  false,
+			outEmail: "alice@example.com",
+		},
+		{
+			name:    "should fail with empty name",
+			inEmail: "alice@example.com",
+			inHash:  "hash",
+			wantErr: true,
+			errType: ErrEmptyUserName,
+		},
+		{
+			name:    "should fail with empty email",
+			inName:  "alice",
+			inHash:  "hash",
+			wantErr: true,
+			errType: ErrEmptyUserEmail,
+		},
+		{
+			name:    "should fail with empty password hash",
+			inName:  "alice",
+			inEmail: "alice@example.com",
+			wantErr: true,
+			errType: ErrEmptyUserPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewUser(tt.inName, tt.inEmail, "", tt.inHash)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errType, err)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.inName, user.Name)
+				assert.Equal(t, tt.outEmail, user.Email)
+				assert.Len(t, user.Uuid, 36)
+				assert.NotEmpty(t, user.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestUser_UpdateProfile(t *testing.T) {
+	user := &User{Id: 1, Name: "alice", Phone: "1234567890"}
+	beforeUpdate := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, ErrEmptyUserName, user.UpdateProfile("", "0987654321"))
+	assert.Equal(t, "alice", user.Name)
+
+	assert.NoError(t, user.UpdateProfile("alice_b", "0987654321"))
+	assert.Equal(t, "alice_b", user.Name)
+	assert.Equal(t, "0987654321", user.Phone)
+	assert.True(t, user.UpdatedAt.After(beforeUpdate))
+}
+
+func TestUserConflictErrors(t *testing.T) {
+	wrapped := fmt.Errorf("register: %w", ErrUserNameTaken)
+
+	assert.True(t, IsUserNameTakenError(wrapped))
+	assert.False(t, IsUserEmailTakenError(wrapped))
+	assert.True(t, IsUserEmailTakenError(ErrUserEmailTaken))
+	assert.False(t, IsUserNameTakenError(ErrExampleNameTaken))
+}
@@ -0,0 +1,8 @@
+package repo
+
+import "context"
+
+// IRolePermissionRepo defines the interface for the role permission repository
+type IRolePermissionRepo interface {
+	LoadRolePermissions(ctx context.Context) (map[string][]string, error)
+}
@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+)
+
+// IUserRepo defines the interface for user repository
+type IUserRepo interface {
+	Create(ctx context.Context, user *model.User) (*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	GetByUUID(ctx context.Context, uuid string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByName(ctx context.Context, name string) (*model.User, error)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/ntdat104/go-clean-architecture/domain/model"
 	"github.com/ntdat104/go-clean-architecture/domain/repo"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
 )
 
 type ExampleRepo struct {
@@ -19,6 +20,11 @@ func NewExampleRepo(db *sqlx.DB) repo.IExampleRepo {
 	return &ExampleRepo{db: db}
 }
 
+// measure times a query in db_query_duration_seconds, labelled with the driver and operation
+func (r *ExampleRepo) measure(operation string, query func() error) error {
+	return metrics.MeasureDBQuery(r.db.DriverName(), "example."+operation, query)
+}
+
 func (r *ExampleRepo) Create(ctx context.Context, example *model.Example) (*model.Example, error) {
 	now := time.Now()
 	example.CreatedAt = now
@@ -29,7 +35,11 @@ func (r *ExampleRepo) Create(ctx context.Context, example *model.Example) (*mode
 		INSERT INTO examples (name, alias, created_at, updated_at)
 		VALUES (?, ?, ?, ?)
 	`
-	result, err := r.db.ExecContext(ctx, query, example.Name, example.Alias, example.CreatedAt, example.UpdatedAt)
+	var result sql.Result
+	err := r.measure("create", func() (err error) {
+		result, err = r.db.ExecContext(ctx, query, example.Name, example.Alias, example.CreatedAt, example.UpdatedAt)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +61,11 @@ func (r *ExampleRepo) Update(ctx context.Context, entity *model.Example) error {
 		SET name = ?, alias = ?, updated_at = ?
 		WHERE id = ?
 	`
-	result, err := r.db.ExecContext(ctx, query, entity.Name, entity.Alias, entity.UpdatedAt, entity.Id)
+	var result sql.Result
+	err := r.measure("update", func() (err error) {
+		result, err = r.db.ExecContext(ctx, query, entity.Name, entity.Alias, entity.UpdatedAt, entity.Id)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -69,7 +83,11 @@ func (r *ExampleRepo) Update(ctx context.Context, entity *model.Example) error {
 
 func (r *ExampleRepo) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM examples WHERE id = ?`
-	result, err := r.db.ExecContext(ctx, query, id)
+	var result sql.Result
+	err := r.measure("delete", func() (err error) {
+		result, err = r.db.ExecContext(ctx, query, id)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -89,7 +107,9 @@ func (r *ExampleRepo) GetByID(ctx context.Context, id int) (*model.Example, erro
 	var example model.Example
 	query := `SELECT id, name, alias, created_at, updated_at FROM examples WHERE id = ?`
 
-	err := r.db.GetContext(ctx, &example, query, id)
+	err := r.measure("get_by_id", func() error {
+		return r.db.GetContext(ctx, &example, query, id)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -104,7 +124,9 @@ func (r *ExampleRepo) FindByName(ctx context.Context, name string) (*model.Examp
 	var example model.Example
 	query := `SELECT id, name, alias, created_at, updated_at FROM examples WHERE name = ?`
 
-	err := r.db.GetContext(ctx, &example, query, name)
+	err := r.measure("find_by_name", func() error {
+		return r.db.GetContext(ctx, &example, query, name)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ntdat104/go-clean-architecture/domain/repo"
+)
+
+type RolePermissionRepo struct {
+	db *sqlx.DB
+}
+
+func NewRolePermissionRepo(db *sqlx.DB) repo.IRolePermissionRepo {
+	return &RolePermissionRepo{db: db}
+}
+
+// LoadRolePermissions returns every role with the permissions granted to it
+func (r *RolePermissionRepo) LoadRolePermissions(ctx context.Context) (map[string][]string, error) {
+	var rows []struct {
+		Role       string `db:"role"`
+		Permission string `db:"permission"`
+	}
+	query := `SELECT role, permission FROM role_permissions ORDER BY role, permission`
+
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	rolePermissions := make(map[string][]string)
+	for _, row := range rows {
+		rolePermissions[row.Role] = append(rolePermissions[row.Role], row.Permission)
+	}
+
+	return rolePermissions, nil
+}
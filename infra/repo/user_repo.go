@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+	"github.com/ntdat104/go-clean-architecture/domain/repo"
+)
+
+const userColumns = `id, uuid, name, email, phone, password_hash, created_at, updated_at`
+
+// mysqlDuplicateEntry is the MySQL error number of a UNIQUE index violation
+const mysqlDuplicateEntry = 1062
+
+// duplicateKey extracts the index of a duplicate entry message, e.g. name from
+// "Duplicate entry 'bob' for key 'users.name'" (MySQL 8) or "... for key 'name'" (MySQL 5.7)
+var duplicateKey = regexp.MustCompile(`for key '(?:[^'.]+\.)?([^'.]+)'$`)
+
+type UserRepo struct {
+	db *sqlx.DB
+}
+
+func NewUserRepo(db *sqlx.DB) repo.IUserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	query := `
+		INSERT INTO users (uuid, name, email, phone, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := r.db.ExecContext(ctx, query, user.Uuid, user.Name, user.Email, user.Phone, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return nil, conflictError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	user.Id = int(id)
+
+	return user, nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *model.User) error {
+	user.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE users
+		SET name = ?, phone = ?, updated_at = ?
+		WHERE uuid = ?
+	`
+	result, err := r.db.ExecContext(ctx, query, user.Name, user.Phone, user.UpdatedAt, user.Uuid)
+	if err != nil {
+		return conflictError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *UserRepo) GetByUUID(ctx context.Context, uuid string) (*model.User, error) {
+	return r.getBy(ctx, "uuid", uuid)
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.getBy(ctx, "email", model.NormalizeEmail(email))
+}
+
+func (r *UserRepo) GetByName(ctx context.Context, name string) (*model.User, error) {
+	return r.getBy(ctx, "name", name)
+}
+
+// getBy loads a single user by a unique column; column is never user input
+func (r *UserRepo) getBy(ctx context.Context, column string, value any) (*model.User, error) {
+	var user model.User
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + column + ` = ?`
+
+	err := r.db.GetContext(ctx, &user, query, value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// conflictError maps a violation of the unique name or email index to the domain error, so a write
+// losing a race with a concurrent one still reports the conflict the service checks for beforehand
+func conflictError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return err
+	}
+
+	match := duplicateKey.FindStringSubmatch(mysqlErr.Message)
+	if match == nil {
+		return err
+	}
+	switch match[1] {
+	case "name":
+		return fmt.Errorf("%w: %v", model.ErrUserNameTaken, err)
+	case "email":
+		return fmt.Errorf("%w: %v", model.ErrUserEmailTaken, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ntdat104/go-clean-architecture/domain/model"
+)
+
+// failingDriver fails every statement with err, standing in for MySQL
+type failingDriver struct {
+	err error
+}
+
+func (d *failingDriver) Open(string) (driver.Conn, error) {
+	return failingConn{d}, nil
+}
+
+type failingConn struct {
+	driver *failingDriver
+}
+
+func (c failingConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, c.driver.err
+}
+
+func (failingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (failingConn) Close() error {
+	return nil
+}
+
+func (failingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestUserRepoReportsDuplicates(t *testing.T) {
+	failing := &failingDriver{}
+	sql.Register("failing-user-repo", failing)
+	db, err := sqlx.Open("failing-user-repo", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	userRepo := NewUserRepo(db)
+	user := &model.User{Uuid: "u-1", Name: "bob", Email: "bob@example.com"}
+
+	failing.err = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bob' for key 'users.name'"}
+	_, err = userRepo.Create(context.Background(), user)
+	assert.True(t, model.IsUserNameTakenError(err))
+	assert.True(t, model.IsUserNameTakenError(userRepo.Update(context.Background(), user)))
+
+	failing.err = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bob@example.com' for key 'email'"}
+	_, err = userRepo.Create(context.Background(), user)
+	assert.True(t, model.IsUserEmailTakenError(err))
+
+	// Other failures are returned as they are
+	failing.err = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'u-1' for key 'users.uuid'"}
+	_, err = userRepo.Create(context.Background(), user)
+	assert.Same(t, failing.err, err)
+	failing.err = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	assert.Same(t, failing.err, userRepo.Update(context.Background(), user))
+}
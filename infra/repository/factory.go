@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/go-redis/redis/v8"
 	_ "github.com/go-sql-driver/mysql" // MySQL driver
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"           // PostgreSQL driver
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 
 	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/tracing"
 )
 
 // NewSqliteConn creates a new SQLite database connection based on the SQLite configuration.
@@ -19,8 +23,8 @@ func NewSqliteConn() (*sqlx.DB, error) {
 		return nil, ErrMissingSQLiteConfig
 	}
 
-	// Use sqlx.Connect to open a new database connection
-	db, err := sqlx.Connect("sqlite3", config.GlobalConfig.SQLite.Dsn)
+	// Open a new database connection, traced and verified with a ping
+	db, err := connectSQL("sqlite3", config.GlobalConfig.SQLite.Dsn, semconv.DBSystemNameSQLite)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open SQLite connection: %w", err)
 	}
@@ -47,7 +51,7 @@ func NewMySQLConn() (*sqlx.DB, error) {
 	)
 
 	// Open database connection with sqlx
-	db, err := sqlx.Connect("mysql", dsn)
+	db, err := connectSQL("mysql", dsn, semconv.DBSystemNameMySQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open MySQL connection: %w", err)
 	}
@@ -76,6 +80,7 @@ func NewRedisConn() (*redis.Client, error) {
 		MinIdleConns: config.GlobalConfig.Redis.MinIdleConns,
 		IdleTimeout:  time.Duration(config.GlobalConfig.Redis.IdleTimeout) * time.Second,
 	})
+	client.AddHook(tracing.NewRedisHook())
 
 	return client, nil
 }
@@ -97,7 +102,7 @@ func NewPostgreConn() (*sqlx.DB, error) {
 	)
 
 	// Open database connection with sqlx
-	db, err := sqlx.Connect("postgres", connString)
+	db, err := connectSQL("postgres", connString, semconv.DBSystemNamePostgreSQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
 	}
@@ -118,3 +123,25 @@ func NewPostgreConn() (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// connectSQL opens a connection pool whose queries are traced as children of the span in their context,
+// and verifies it with a ping like sqlx.Connect
+func connectSQL(driverName, dsn string, system attribute.KeyValue) (*sqlx.DB, error) {
+	sqlDB, err := otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(sqlDB, driverName)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
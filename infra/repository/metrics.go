@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
+)
+
+// RegisterPoolMetrics exposes the connection pools of every open backend of client in the metrics registry
+func RegisterPoolMetrics(client *Client) error {
+	var errs []error
+	for name, db := range map[string]*sqlx.DB{
+		BackendMySQL:      client.MySQL,
+		BackendPostgreSQL: client.PostgreSQL,
+		BackendSQLite:     client.SQLite,
+	} {
+		if db == nil {
+			continue
+		}
+		errs = append(errs, metrics.Register(metrics.NewDBStatsCollector(name, db.Stats)))
+	}
+	if client.Redis != nil {
+		errs = append(errs, metrics.Register(metrics.NewRedisPoolCollector(BackendRedis, client.Redis.PoolStats)))
+	}
+	return errors.Join(errs...)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/ntdat104/go-clean-architecture/config"
 	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+	"github.com/ntdat104/go-clean-architecture/pkg/metrics"
 )
 
 // ClientOptions holds Redis client configuration options
@@ -138,7 +139,7 @@ func (c *RedisClient) HealthCheck(ctx context.Context) error {
 
 // Close closes the Redis connection
 func (c *RedisClient) Close() error {
-	logger.Logger.Info("Closing Redis connection")
+	logger.ForComponent(context.Background(), logger.ComponentRepository).Info("Closing Redis connection")
 	return c.Client.Close()
 }
 
@@ -147,6 +148,11 @@ func (c *RedisClient) Stats() *redis.PoolStats {
 	return c.Client.PoolStats()
 }
 
+// RegisterMetrics exposes the connection pool stats in the metrics registry, labelled with name
+func (c *RedisClient) RegisterMetrics(name string) error {
+	return metrics.Register(metrics.NewRedisPoolCollector(name, c.Stats))
+}
+
 // WithTimeout returns a new context with timeout
 func (c *RedisClient) WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(ctx, timeout)
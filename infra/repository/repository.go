@@ -1,17 +1,45 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// Backend names selectable in repository.backends
+const (
+	BackendMySQL      = "mysql"
+	BackendPostgreSQL = "postgres"
+	BackendSQLite     = "sqlite"
+	BackendRedis      = "redis"
+)
+
+// MySQLSchemaFile is the schema applied to MySQL when repository.migrate is enabled
+const MySQLSchemaFile = "./schema/mysql.sql"
+
+// Default connection retry settings used when they are not configured
+const (
+	DefaultConnectAttempts = 5
+	DefaultRetryBackoff    = 500 * time.Millisecond
+	DefaultMaxRetryBackoff = 10 * time.Second
 )
 
-type RepositoryOption func(*Client)
+// RepositoryOption opens one backend of the client
+type RepositoryOption func(ctx context.Context, c *Client) error
 
-// Clients holds all the database and client connections.
+// Client holds all the database and client connections.
 type Client struct {
 	MySQL      *sqlx.DB
 	PostgreSQL *sqlx.DB
@@ -19,80 +47,305 @@ type Client struct {
 	Redis      *redis.Client
 }
 
-// InitClients initializes and returns a Client struct with all database connections.
-func InitializeRepositories(opts ...RepositoryOption) *Client {
+// PoolStats is a snapshot of the connection pools of a client, keyed by backend name
+type PoolStats struct {
+	SQL   map[string]sql.DBStats
+	Redis *redis.PoolStats
+}
+
+// InitializeRepositories opens the backends selected by opts. If one fails, the connections
+// already opened are closed and the error is returned.
+func InitializeRepositories(ctx context.Context, opts ...RepositoryOption) (*Client, error) {
 	client := &Client{}
 	for _, opt := range opts {
-		opt(client)
+		if err := opt(ctx, client); err != nil {
+			if closeErr := client.Close(); closeErr != nil {
+				return nil, errors.Join(err, closeErr)
+			}
+			return nil, err
+		}
 	}
-	return client
+	return client, nil
+}
+
+// NewClientFromConfig opens the backends listed in repository.backends
+func NewClientFromConfig(ctx context.Context) (*Client, error) {
+	repoConfig := config.GlobalConfig.Repository
+	if repoConfig == nil || len(repoConfig.Backends) == 0 {
+		return nil, fmt.Errorf("%w: repository.backends is empty", ErrUnsupportedStoreType)
+	}
+
+	opts := make([]RepositoryOption, 0, len(repoConfig.Backends))
+	for _, backend := range repoConfig.Backends {
+		switch backend {
+		case BackendMySQL:
+			opts = append(opts, WithMySQL())
+		case BackendPostgreSQL:
+			opts = append(opts, WithMyPostgreSQL())
+		case BackendSQLite:
+			opts = append(opts, WithMySQLite())
+		case BackendRedis:
+			opts = append(opts, WithRedis())
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedStoreType, backend)
+		}
+	}
+
+	return InitializeRepositories(ctx, opts...)
 }
 
 // WithMySQLite returns an option to initialize SQLite
 func WithMySQLite() RepositoryOption {
-	return func(c *Client) {
-		if c.SQLite == nil {
-			sqlite, err := NewSqliteConn()
-			if err != nil {
-				panic("Failed to initialize SQLite: " + err.Error())
-			}
-			c.SQLite = sqlite
+	return func(ctx context.Context, c *Client) error {
+		if c.SQLite != nil {
+			return nil
 		}
+		sqlite, err := connectWithRetry(ctx, BackendSQLite, NewSqliteConn)
+		if err != nil {
+			return fmt.Errorf("failed to initialize SQLite: %w", err)
+		}
+		c.SQLite = sqlite
+		return nil
 	}
 }
 
-// WithMySQL returns an option to initialize MySQL
+// WithMySQL returns an option to initialize MySQL, applying the schema when repository.migrate is enabled
 func WithMySQL() RepositoryOption {
-	return func(c *Client) {
-		if c.MySQL == nil {
-			mysql, err := NewMySQLConn()
-			if err != nil {
-				panic("Failed to initialize MySQL: " + err.Error())
-			}
-			if err := RunMigration(mysql, "./schema/mysql.sql"); err != nil {
-				log.Fatalf("Migration failed: %v", err)
+	return func(ctx context.Context, c *Client) error {
+		if c.MySQL != nil {
+			return nil
+		}
+		mysql, err := connectWithRetry(ctx, BackendMySQL, NewMySQLConn)
+		if err != nil {
+			return fmt.Errorf("failed to initialize MySQL: %w", err)
+		}
+		c.MySQL = mysql
+
+		if repoConfig := config.GlobalConfig.Repository; repoConfig != nil && repoConfig.Migrate {
+			if err := RunMigration(mysql, MySQLSchemaFile); err != nil {
+				return fmt.Errorf("migration failed: %w", err)
 			}
-			c.MySQL = mysql
 		}
+		return nil
 	}
 }
 
 // WithMyPostgreSQL returns an option to initialize PostgreSQL
 func WithMyPostgreSQL() RepositoryOption {
-	return func(c *Client) {
-		if c.PostgreSQL == nil {
-			postgre, err := NewPostgreConn()
-			if err != nil {
-				panic("Failed to initialize PostgreSQL: " + err.Error())
-			}
-			c.PostgreSQL = postgre
+	return func(ctx context.Context, c *Client) error {
+		if c.PostgreSQL != nil {
+			return nil
 		}
+		postgre, err := connectWithRetry(ctx, BackendPostgreSQL, NewPostgreConn)
+		if err != nil {
+			return fmt.Errorf("failed to initialize PostgreSQL: %w", err)
+		}
+		c.PostgreSQL = postgre
+		return nil
 	}
 }
 
-// WithRedis returns an option to initialize Redis
+// WithRedis returns an option to initialize Redis, verifying the server answers
 func WithRedis() RepositoryOption {
-	return func(c *Client) {
-		if c.Redis == nil {
-			redis, err := NewRedisConn()
+	return func(ctx context.Context, c *Client) error {
+		if c.Redis != nil {
+			return nil
+		}
+		rdb, err := connectWithRetry(ctx, BackendRedis, func() (*redis.Client, error) {
+			client, err := NewRedisConn()
 			if err != nil {
-				panic("Failed to initialize Redis: " + err.Error())
+				return nil, err
 			}
-			c.Redis = redis
+			if err := client.Ping(ctx).Err(); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("failed to ping Redis: %w", err)
+			}
+			return client, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize Redis: %w", err)
+		}
+		c.Redis = rdb
+		return nil
+	}
+}
+
+// DB returns the primary SQL database: MySQL, then PostgreSQL, then SQLite
+func (c *Client) DB() *sqlx.DB {
+	switch {
+	case c.MySQL != nil:
+		return c.MySQL
+	case c.PostgreSQL != nil:
+		return c.PostgreSQL
+	default:
+		return c.SQLite
+	}
+}
+
+// Stats returns the current state of every open connection pool
+func (c *Client) Stats() *PoolStats {
+	stats := &PoolStats{SQL: make(map[string]sql.DBStats)}
+	if c.MySQL != nil {
+		stats.SQL[BackendMySQL] = c.MySQL.Stats()
+	}
+	if c.PostgreSQL != nil {
+		stats.SQL[BackendPostgreSQL] = c.PostgreSQL.Stats()
+	}
+	if c.SQLite != nil {
+		stats.SQL[BackendSQLite] = c.SQLite.Stats()
+	}
+	if c.Redis != nil {
+		stats.Redis = c.Redis.PoolStats()
+	}
+	return stats
+}
+
+// Close closes every open connection, returning all errors
+func (c *Client) Close() error {
+	var errs []error
+	if c.Redis != nil {
+		if err := c.Redis.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close Redis: %w", err))
+		}
+	}
+	for name, db := range map[string]*sqlx.DB{
+		BackendMySQL:      c.MySQL,
+		BackendPostgreSQL: c.PostgreSQL,
+		BackendSQLite:     c.SQLite,
+	} {
+		if db == nil {
+			continue
+		}
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// connectWithRetry calls connect until it succeeds, doubling the wait between attempts up to the configured maximum
+func connectWithRetry[T any](ctx context.Context, name string, connect func() (T, error)) (T, error) {
+	attempts, backoff, maxBackoff := retrySettings()
+
+	var conn T
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		conn, err = connect()
+		if err == nil {
+			return conn, nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		logger.SugarForComponent(ctx, logger.ComponentRepository).Warnf("Connecting to %s failed (attempt %d/%d), retrying in %s: %v", name, attempt, attempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return conn, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+	return conn, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
+
+// retrySettings returns the configured connection retry settings, falling back to the defaults
+func retrySettings() (int, time.Duration, time.Duration) {
+	attempts, backoff, maxBackoff := DefaultConnectAttempts, DefaultRetryBackoff, DefaultMaxRetryBackoff
+	if repoConfig := config.GlobalConfig.Repository; repoConfig != nil {
+		if repoConfig.ConnectAttempts > 0 {
+			attempts = repoConfig.ConnectAttempts
+		}
+		if d := config.GetDuration(repoConfig.RetryBackoff); d > 0 {
+			backoff = d
+		}
+		if d := config.GetDuration(repoConfig.MaxRetryBackoff); d > 0 {
+			maxBackoff = d
 		}
 	}
+	return attempts, backoff, max(backoff, maxBackoff)
 }
 
+// MySQL error numbers of schema changes which were already applied
+const (
+	mysqlDuplicateColumn = 1060
+	mysqlDuplicateKey    = 1061
+)
+
+// RunMigration executes the statements of a schema file against db one by one, since the drivers
+// run a single statement per call unless multi-statement mode is enabled. The file is applied on
+// every start: statements adding a column or index which already exists are skipped.
 func RunMigration(db *sqlx.DB, schemaFile string) error {
-	sqlBytes, err := ioutil.ReadFile(schemaFile)
+	sqlBytes, err := os.ReadFile(schemaFile)
 	if err != nil {
 		return fmt.Errorf("failed to read schema file: %w", err)
 	}
 
-	// Execute the entire SQL file
-	_, err = db.Exec(string(sqlBytes))
-	if err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+	for i, statement := range splitStatements(string(sqlBytes)) {
+		if _, err := db.Exec(statement); err != nil && !alreadyApplied(err) {
+			return fmt.Errorf("failed to execute migration statement %d: %w", i+1, err)
+		}
 	}
 	return nil
 }
+
+// alreadyApplied reports whether err rejects adding a column or index which already exists
+func alreadyApplied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlDuplicateColumn || mysqlErr.Number == mysqlDuplicateKey)
+}
+
+// splitStatements splits an SQL script on the semicolons ending its statements, ignoring those in
+// quoted strings, identifiers and comments. Statements left empty, such as trailing comments, are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	// hasCode tells whether the current statement holds more than comments and spaces
+	hasCode := false
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// Copy the quoted text up to its closing quote, skipping escaped characters
+			end := i + 1
+			for end < len(script) && script[end] != c {
+				if script[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(script)-1)
+			current.WriteString(script[i : end+1])
+			i, hasCode = end, true
+		case strings.HasPrefix(script[i:], "--") || c == '#':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end - 1
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 4
+			}
+			current.WriteString(script[i : i+end+4])
+			i += end + 3
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+			hasCode = hasCode || !unicode.IsSpace(rune(c))
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+func setupRepositoryConfig(t *testing.T, repoConfig *config.RepositoryConfig) {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{Repository: repoConfig}
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	setupRepositoryConfig(t, &config.RepositoryConfig{
+		ConnectAttempts: 3,
+		RetryBackoff:    "1ms",
+		MaxRetryBackoff: "2ms",
+	})
+
+	calls := 0
+	value, err := connectWithRetry(context.Background(), "test", func() (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("not ready")
+		}
+		return "connected", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "connected", value)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	failure := errors.New("refused")
+	_, err = connectWithRetry(context.Background(), "test", func() (string, error) {
+		calls++
+		return "", failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, 3, calls)
+}
+
+func TestConnectWithRetryStopsOnCancel(t *testing.T) {
+	setupRepositoryConfig(t, &config.RepositoryConfig{ConnectAttempts: 10, RetryBackoff: "1h"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := connectWithRetry(ctx, "test", func() (int, error) {
+		return 0, errors.New("refused")
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestInitializeRepositoriesClosesOnFailure(t *testing.T) {
+	setupRepositoryConfig(t, &config.RepositoryConfig{})
+
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	withTestRedis := func(ctx context.Context, c *Client) error {
+		c.Redis = rdb
+		return nil
+	}
+	failure := errors.New("mysql unreachable")
+	failing := func(ctx context.Context, c *Client) error {
+		return failure
+	}
+
+	client, err := InitializeRepositories(context.Background(), withTestRedis, failing)
+	assert.ErrorIs(t, err, failure)
+	assert.Nil(t, client)
+	// The Redis connection opened before the failure has been closed
+	assert.Error(t, rdb.Ping(context.Background()).Err())
+}
+
+func TestClientStatsAndClose(t *testing.T) {
+	setupRepositoryConfig(t, &config.RepositoryConfig{})
+
+	server := miniredis.RunT(t)
+	client := &Client{Redis: redis.NewClient(&redis.Options{Addr: server.Addr()})}
+	require.NoError(t, client.Redis.Ping(context.Background()).Err())
+
+	stats := client.Stats()
+	assert.Empty(t, stats.SQL)
+	require.NotNil(t, stats.Redis)
+	assert.EqualValues(t, 1, stats.Redis.TotalConns)
+	assert.Nil(t, client.DB())
+
+	assert.NoError(t, client.Close())
+}
+
+func TestNewClientFromConfigRejectsUnknownBackend(t *testing.T) {
+	setupRepositoryConfig(t, &config.RepositoryConfig{Backends: []string{"oracle"}})
+
+	_, err := NewClientFromConfig(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedStoreType)
+}
+
+// singleStatementDriver records the statements it executes and rejects calls holding several,
+// as MySQL does without multiStatements=true. Statements fail with the error reject returns, if set.
+type singleStatementDriver struct {
+	executed []string
+	reject   func(query string) error
+}
+
+func (d *singleStatementDriver) Open(string) (driver.Conn, error) {
+	return singleStatementConn{d}, nil
+}
+
+type singleStatementConn struct {
+	driver *singleStatementDriver
+}
+
+func (c singleStatementConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if len(splitStatements(query)) != 1 {
+		return nil, fmt.Errorf("Error 1064 (42000): You have an error in your SQL syntax near %q", query)
+	}
+	c.driver.executed = append(c.driver.executed, query)
+	if c.driver.reject != nil {
+		if err := c.driver.reject(query); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (singleStatementConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (singleStatementConn) Close() error {
+	return nil
+}
+
+func (singleStatementConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestRunMigrationExecutesSchemaFile(t *testing.T) {
+	recorder := &singleStatementDriver{}
+	sql.Register("single-statement", recorder)
+	db, err := sqlx.Open("single-statement", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	require.NoError(t, RunMigration(db, "../../"+MySQLSchemaFile))
+	require.Len(t, recorder.executed, 5)
+	assert.True(t, strings.HasPrefix(recorder.executed[0], "-- tb_users\n"))
+	assert.Contains(t, recorder.executed[0], "CREATE TABLE IF NOT EXISTS users (")
+	assert.Contains(t, recorder.executed[1], "ALTER TABLE users ADD COLUMN phone")
+	assert.Contains(t, recorder.executed[4], "CREATE TABLE IF NOT EXISTS role_permissions")
+
+	// Running the whole file at once is what the driver rejects
+	script, err := os.ReadFile("../../" + MySQLSchemaFile)
+	require.NoError(t, err)
+	_, err = db.Exec(string(script))
+	assert.Error(t, err)
+}
+
+func TestRunMigrationSkipsAppliedChanges(t *testing.T) {
+	// The schema was applied by an earlier start: the columns and indexes added to upgrade it exist
+	var reject error
+	recorder := &singleStatementDriver{reject: func(query string) error {
+		if strings.Contains(query, "ALTER TABLE") {
+			return reject
+		}
+		return nil
+	}}
+	sql.Register("applied-schema", recorder)
+	db, err := sqlx.Open("applied-schema", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, number := range []uint16{mysqlDuplicateColumn, mysqlDuplicateKey} {
+		reject = &mysql.MySQLError{Number: number, Message: "already exists"}
+		assert.NoError(t, RunMigration(db, "../../"+MySQLSchemaFile))
+	}
+
+	// Other failures, such as accounts sharing a name, stop the migration
+	reject = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bob' for key 'users.name'"}
+	assert.ErrorContains(t, RunMigration(db, "../../"+MySQLSchemaFile), "migration statement 2")
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- leading comment
+INSERT INTO t (a, b) VALUES ('x;y', "z;");
+/* block; comment */ UPDATE ` + "`odd;name`" + ` SET a = 'it\'s;';
+# trailing comment;
+`
+	assert.Equal(t, []string{
+		"-- leading comment\nINSERT INTO t (a, b) VALUES ('x;y', \"z;\")",
+		"/* block; comment */ UPDATE `odd;name` SET a = 'it\\'s;'",
+	}, splitStatements(script))
+}
@@ -0,0 +1,173 @@
+// Package authz provides role and permission based authorization
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/errors"
+)
+
+// Policy sources
+const (
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
+
+// Wildcard grants every permission, or every action of a resource when used as "resource:*"
+const Wildcard = "*"
+
+// Permission is an action on a resource, written as "resource:action" (e.g. examples:write)
+type Permission string
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Roles   []string
+}
+
+// HasRole reports whether the principal holds the given role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Policy maps roles to the permissions they grant
+type Policy struct {
+	roles map[string][]Permission
+}
+
+// NewPolicy creates a policy from a role to permissions mapping
+func NewPolicy(rolePermissions map[string][]string) *Policy {
+	roles := make(map[string][]Permission, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		key := strings.ToLower(role)
+		for _, perm := range perms {
+			roles[key] = append(roles[key], Permission(strings.TrimSpace(perm)))
+		}
+	}
+	return &Policy{roles: roles}
+}
+
+// Allows reports whether any role of the principal grants the permission
+func (p *Policy) Allows(principal *Principal, perm Permission) bool {
+	if p == nil || principal == nil {
+		return false
+	}
+	for _, role := range principal.Roles {
+		for _, granted := range p.roles[strings.ToLower(role)] {
+			if matches(granted, perm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches checks a granted permission against a required one, honouring wildcards
+func matches(granted, required Permission) bool {
+	if granted == Wildcard || granted == required {
+		return true
+	}
+	resource, action, ok := strings.Cut(string(granted), ":")
+	if !ok || action != Wildcard {
+		return false
+	}
+	return strings.HasPrefix(string(required), resource+":")
+}
+
+// PolicySource loads the role to permissions mapping a policy is built from
+type PolicySource interface {
+	LoadRolePermissions(ctx context.Context) (map[string][]string, error)
+}
+
+// ConfigSource is a PolicySource backed by the authz section of the configuration
+type ConfigSource struct {
+	cfg *config.AuthzConfig
+}
+
+// NewConfigSource creates a policy source reading roles from configuration
+func NewConfigSource(cfg *config.AuthzConfig) *ConfigSource {
+	return &ConfigSource{cfg: cfg}
+}
+
+// LoadRolePermissions returns the roles defined in configuration
+func (s *ConfigSource) LoadRolePermissions(ctx context.Context) (map[string][]string, error) {
+	if s.cfg == nil {
+		return nil, fmt.Errorf("authz configuration is missing")
+	}
+	return s.cfg.Roles, nil
+}
+
+// Authorizer checks principals against the current policy
+type Authorizer struct {
+	source PolicySource
+	mu     sync.RWMutex
+	policy *Policy
+}
+
+// NewAuthorizer creates an authorizer that denies everything until Reload succeeds
+func NewAuthorizer(source PolicySource) *Authorizer {
+	return &Authorizer{
+		source: source,
+		policy: NewPolicy(nil),
+	}
+}
+
+// Reload rebuilds the policy from the source, keeping the previous one on failure
+func (a *Authorizer) Reload(ctx context.Context) error {
+	rolePermissions, err := a.source.LoadRolePermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load authorization policy: %w", err)
+	}
+
+	policy := NewPolicy(rolePermissions)
+	a.mu.Lock()
+	a.policy = policy
+	a.mu.Unlock()
+	return nil
+}
+
+// Policy returns the policy currently in effect
+func (a *Authorizer) Policy() *Policy {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.policy
+}
+
+// Check verifies that the principal holds the permission
+func (a *Authorizer) Check(principal *Principal, perm Permission) error {
+	if principal == nil {
+		return errors.NewUnauthorizedError("authentication required", nil)
+	}
+	if !a.Policy().Allows(principal, perm) {
+		return errors.Newf(errors.ErrorTypeForbidden, "subject '%s' lacks permission '%s'", principal.Subject, perm)
+	}
+	return nil
+}
+
+// Authorize verifies that the principal carried by ctx holds the permission
+func (a *Authorizer) Authorize(ctx context.Context, perm Permission) error {
+	principal, _ := PrincipalFromContext(ctx)
+	return a.Check(principal, perm)
+}
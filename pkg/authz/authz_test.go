@@ -0,0 +1,96 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	apperrors "github.com/ntdat104/go-clean-architecture/pkg/errors"
+)
+
+type stubSource struct {
+	roles map[string][]string
+	err   error
+}
+
+func (s stubSource) LoadRolePermissions(ctx context.Context) (map[string][]string, error) {
+	return s.roles, s.err
+}
+
+func TestPolicyAllows(t *testing.T) {
+	policy := NewPolicy(map[string][]string{
+		"admin":  {"*"},
+		"Editor": {"examples:*"},
+		"viewer": {"examples:read"},
+	})
+
+	tests := []struct {
+		name     string
+		roles    []string
+		perm     Permission
+		expected bool
+	}{
+		{"admin wildcard", []string{"admin"}, "users:write", true},
+		{"resource wildcard", []string{"editor"}, "examples:write", true},
+		{"resource wildcard other resource", []string{"editor"}, "users:read", false},
+		{"exact permission", []string{"viewer"}, "examples:read", true},
+		{"missing permission", []string{"viewer"}, "examples:write", false},
+		{"role case insensitive", []string{"VIEWER"}, "examples:read", true},
+		{"unknown role", []string{"guest"}, "examples:read", false},
+		{"no roles", nil, "examples:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &Principal{Subject: "user-1", Roles: tt.roles}
+			assert.Equal(t, tt.expected, policy.Allows(principal, tt.perm))
+		})
+	}
+
+	assert.False(t, policy.Allows(nil, "examples:read"))
+}
+
+func TestAuthorizerCheck(t *testing.T) {
+	authorizer := NewAuthorizer(NewConfigSource(&config.AuthzConfig{
+		Roles: map[string][]string{"viewer": {"examples:read"}},
+	}))
+
+	// Deny everything before the policy is loaded
+	principal := &Principal{Subject: "user-1", Roles: []string{"viewer"}}
+	assert.True(t, apperrors.IsForbiddenError(authorizer.Check(principal, "examples:read")))
+
+	require.NoError(t, authorizer.Reload(context.Background()))
+	assert.NoError(t, authorizer.Check(principal, "examples:read"))
+
+	err := authorizer.Check(principal, "examples:write")
+	assert.True(t, apperrors.IsForbiddenError(err))
+	assert.Equal(t, 403, err.(*apperrors.AppError).StatusCode())
+
+	err = authorizer.Check(nil, "examples:read")
+	assert.True(t, apperrors.IsUnauthorizedError(err))
+}
+
+func TestAuthorizerAuthorizeFromContext(t *testing.T) {
+	authorizer := NewAuthorizer(stubSource{roles: map[string][]string{"editor": {"examples:write"}}})
+	require.NoError(t, authorizer.Reload(context.Background()))
+
+	ctx := WithPrincipal(context.Background(), &Principal{Subject: "user-2", Roles: []string{"editor"}})
+	assert.NoError(t, authorizer.Authorize(ctx, "examples:write"))
+	assert.True(t, apperrors.IsUnauthorizedError(authorizer.Authorize(context.Background(), "examples:write")))
+}
+
+func TestAuthorizerReloadKeepsPolicyOnError(t *testing.T) {
+	source := &stubSource{roles: map[string][]string{"viewer": {"examples:read"}}}
+	authorizer := NewAuthorizer(source)
+	require.NoError(t, authorizer.Reload(context.Background()))
+
+	source.err = errors.New("database unavailable")
+	assert.Error(t, authorizer.Reload(context.Background()))
+
+	principal := &Principal{Subject: "user-1", Roles: []string{"viewer"}}
+	assert.NoError(t, authorizer.Check(principal, "examples:read"))
+}
@@ -0,0 +1,165 @@
+// Package container is a small dependency injection container resolving singletons by type
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/lifecycle"
+)
+
+// ErrNotProvided is returned when no provider is registered for a type
+var ErrNotProvided = errors.New("no provider registered")
+
+// ErrCycle is returned when providers depend on each other
+var ErrCycle = errors.New("dependency cycle")
+
+// provider builds the instance of one type
+type provider struct {
+	build    func(c *Container) (any, error)
+	override bool
+}
+
+// Container holds providers and the singletons they built, together with the lifecycle hooks they registered.
+// It is meant for wiring at startup; resolve from one goroutine at a time.
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	instances map[reflect.Type]any
+	resolving []reflect.Type
+	hooks     []lifecycle.Hook
+}
+
+// New creates an empty container
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+		instances: make(map[reflect.Type]any),
+	}
+}
+
+// Provide registers how to build T. The provider runs once, on first resolution.
+// A type already registered with Override keeps its override, so tests can swap implementations
+// before modules register their defaults.
+func Provide[T any](c *Container, build func(c *Container) (T, error)) {
+	register(c, build, false)
+}
+
+// Override registers how to build T, taking precedence over every Provide for the same type
+func Override[T any](c *Container, build func(c *Container) (T, error)) {
+	register(c, build, true)
+}
+
+// Supply registers an already built value of T
+func Supply[T any](c *Container, value T) {
+	Provide(c, func(*Container) (T, error) { return value, nil })
+}
+
+// Resolve returns the singleton of T, building it and its dependencies on first use
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	value, err := c.resolve(typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	if value == nil {
+		// Providers may deliberately return a nil interface or pointer, e.g. for disabled features
+		return zero, nil
+	}
+	return value.(T), nil
+}
+
+// MustResolve is Resolve for wiring code where a missing dependency is a programming error
+func MustResolve[T any](c *Container) T {
+	value, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// AppendHook registers a lifecycle hook for a component built by a provider
+func (c *Container) AppendHook(hook lifecycle.Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// Hooks returns the registered lifecycle hooks in registration order
+func (c *Container) Hooks() []lifecycle.Hook {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]lifecycle.Hook(nil), c.hooks...)
+}
+
+// register stores a provider, discarding cached instances so a replacement takes effect
+func register[T any](c *Container, build func(c *Container) (T, error), override bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := typeOf[T]()
+	if existing, ok := c.providers[key]; ok && existing.override && !override {
+		return
+	}
+
+	c.providers[key] = &provider{
+		build:    func(c *Container) (any, error) { return build(c) },
+		override: override,
+	}
+	delete(c.instances, key)
+}
+
+// resolve builds or returns the cached instance for key.
+// The lock is released while a provider runs so it can resolve its own dependencies.
+func (c *Container) resolve(key reflect.Type) (any, error) {
+	c.mu.Lock()
+	if instance, ok := c.instances[key]; ok {
+		c.mu.Unlock()
+		return instance, nil
+	}
+
+	p, ok := c.providers[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s", ErrNotProvided, key)
+	}
+
+	for _, pending := range c.resolving {
+		if pending == key {
+			chain := c.chain(key)
+			c.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrCycle, chain)
+		}
+	}
+	c.resolving = append(c.resolving, key)
+	c.mu.Unlock()
+
+	instance, err := p.build(c)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolving = c.resolving[:len(c.resolving)-1]
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w", key, err)
+	}
+	c.instances[key] = instance
+	return instance, nil
+}
+
+// chain describes the types being resolved, ending with key
+func (c *Container) chain(key reflect.Type) string {
+	names := make([]string, 0, len(c.resolving)+1)
+	for _, t := range c.resolving {
+		names = append(names, t.String())
+	}
+	names = append(names, key.String())
+	return strings.Join(names, " -> ")
+}
+
+// typeOf returns the reflect type of T, including interface types
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
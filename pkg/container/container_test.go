@@ -0,0 +1,100 @@
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{ name string }
+
+func (g *englishGreeter) Greet() string { return "hello " + g.name }
+
+type fakeGreeter struct{}
+
+func (fakeGreeter) Greet() string { return "fake" }
+
+func TestResolveSingleton(t *testing.T) {
+	c := New()
+	builds := 0
+	Supply(c, "bob")
+	Provide(c, func(c *Container) (greeter, error) {
+		builds++
+		name, err := Resolve[string](c)
+		if err != nil {
+			return nil, err
+		}
+		return &englishGreeter{name: name}, nil
+	})
+
+	first := MustResolve[greeter](c)
+	second := MustResolve[greeter](c)
+	assert.Equal(t, "hello bob", first.Greet())
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, builds)
+}
+
+func TestOverrideWinsOverProvide(t *testing.T) {
+	c := New()
+	Override(c, func(*Container) (greeter, error) { return fakeGreeter{}, nil })
+	// Modules registering their defaults afterwards do not replace the override
+	Provide(c, func(*Container) (greeter, error) { return &englishGreeter{}, nil })
+
+	assert.Equal(t, "fake", MustResolve[greeter](c).Greet())
+}
+
+func TestResolveErrors(t *testing.T) {
+	c := New()
+
+	_, err := Resolve[greeter](c)
+	assert.ErrorIs(t, err, ErrNotProvided)
+
+	Provide(c, func(c *Container) (greeter, error) {
+		_, err := Resolve[string](c)
+		return nil, err
+	})
+	Provide(c, func(c *Container) (string, error) {
+		_, err := Resolve[greeter](c)
+		return "", err
+	})
+	_, err = Resolve[greeter](c)
+	assert.ErrorIs(t, err, ErrCycle)
+
+	failure := errors.New("boom")
+	Provide(c, func(*Container) (int, error) { return 0, failure })
+	_, err = Resolve[int](c)
+	assert.ErrorIs(t, err, failure)
+	assert.Panics(t, func() { MustResolve[int](c) })
+}
+
+func TestNilProvider(t *testing.T) {
+	c := New()
+	Provide(c, func(*Container) (greeter, error) { return nil, nil })
+	Provide(c, func(*Container) (*englishGreeter, error) { return nil, nil })
+
+	g, err := Resolve[greeter](c)
+	require.NoError(t, err)
+	assert.Nil(t, g)
+
+	p, err := Resolve[*englishGreeter](c)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestHooks(t *testing.T) {
+	c := New()
+	c.AppendHook(lifecycle.Hook{Name: "first"})
+	c.AppendHook(lifecycle.Hook{Name: "second"})
+
+	hooks := c.Hooks()
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "first", hooks[0].Name)
+	assert.Equal(t, "second", hooks[1].Name)
+}
@@ -0,0 +1,159 @@
+// Package i18n provides the message catalog translating error and response messages
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of last resort, every message exists in it
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Default is the catalog of the locale files shipped with the application
+var Default = mustLoadDefault()
+
+// Catalog holds messages by locale and key. Keys are error codes such as "10001" or dotted
+// names such as "validation.phone"; messages may hold fmt placeholders such as %[1]s.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+	// names maps normalized locales to the names they were added with
+	names map[string]string
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{
+		messages: make(map[string]map[string]string),
+		names:    make(map[string]string),
+	}
+}
+
+// Add merges messages into locale, replacing the existing messages with the same keys
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	normalized := normalize(locale)
+	if _, ok := c.messages[normalized]; !ok {
+		c.messages[normalized] = make(map[string]string, len(messages))
+		c.names[normalized] = locale
+	}
+	for key, message := range messages {
+		c.messages[normalized][key] = message
+	}
+}
+
+// LoadFS adds the JSON objects of messages in the files of fsys matching pattern, each file
+// named after its locale, e.g. locales/vi.json
+func (c *Catalog) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return fmt.Errorf("locale file %s: %w", file, err)
+		}
+		c.Add(strings.TrimSuffix(path.Base(file), path.Ext(file)), messages)
+	}
+	return nil
+}
+
+// Locales returns the sorted locales of the catalog
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.names))
+	for _, name := range c.names {
+		locales = append(locales, name)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Match returns the first of the preferred languages the catalog has, trying the base language
+// of a regional one, e.g. vi for vi-VN. It returns false when none is supported.
+func (c *Catalog) Match(languages ...string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, language := range languages {
+		for _, candidate := range fallbacks(language) {
+			if name, ok := c.names[candidate]; ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Message returns the message of key in locale, falling back to the base language of locale
+// and then to DefaultLocale. It returns false when none of them has the key.
+func (c *Catalog) Message(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range append(fallbacks(locale), DefaultLocale) {
+		if message, ok := c.messages[candidate][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Message returns the message of key in locale from the Default catalog
+func Message(locale, key string) (string, bool) {
+	return Default.Message(locale, key)
+}
+
+// Format returns the message of key in locale from the Default catalog with its placeholders
+// replaced by args, or fallback when the catalog has no message for key
+func Format(locale, key, fallback string, args ...any) string {
+	message, ok := Default.Message(locale, key)
+	if !ok {
+		message = fallback
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// fallbacks returns the normalized locale followed by its base language
+func fallbacks(locale string) []string {
+	locale = normalize(locale)
+	if base, _, found := strings.Cut(locale, "_"); found {
+		return []string{locale, base}
+	}
+	return []string{locale}
+}
+
+// normalize makes locales comparable, e.g. vi-VN and vi_vn
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "-", "_"))
+}
+
+func mustLoadDefault() *Catalog {
+	catalog := NewCatalog()
+	if err := catalog.LoadFS(locales, "locales/*.json"); err != nil {
+		panic("Load locales fail : " + err.Error())
+	}
+	return catalog
+}
@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogFallbacks(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add("en", map[string]string{"greeting": "hello", "farewell": "bye"})
+	catalog.Add("vi", map[string]string{"greeting": "xin chào"})
+	catalog.Add("pt-BR", map[string]string{"greeting": "olá"})
+
+	message, ok := catalog.Message("vi-VN", "greeting")
+	assert.True(t, ok)
+	assert.Equal(t, "xin chào", message)
+
+	// Missing messages fall back to the default locale
+	message, ok = catalog.Message("vi", "farewell")
+	assert.True(t, ok)
+	assert.Equal(t, "bye", message)
+
+	message, _ = catalog.Message("pt_br", "greeting")
+	assert.Equal(t, "olá", message)
+
+	_, ok = catalog.Message("vi", "unknown")
+	assert.False(t, ok)
+
+	locale, ok := catalog.Match("fr", "VI-vn", "en")
+	assert.True(t, ok)
+	assert.Equal(t, "vi", locale)
+	_, ok = catalog.Match("fr")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"en", "pt-BR", "vi"}, catalog.Locales())
+}
+
+func TestCatalogLoadFS(t *testing.T) {
+	catalog := NewCatalog()
+	require.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"10002": "record %[1]v not found"}`)},
+		"locales/vi.json": {Data: []byte(`{"10002": "không tìm thấy bản ghi %[1]v"}`)},
+	}, "locales/*.json"))
+
+	message, _ := catalog.Message("vi", "10002")
+	assert.Equal(t, "không tìm thấy bản ghi %[1]v", message)
+
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{"locales/en.json": {Data: []byte(`[]`)}}, "locales/*.json"))
+}
+
+func TestDefaultCatalogIsComplete(t *testing.T) {
+	for _, locale := range Default.Locales() {
+		for key := range Default.messages[DefaultLocale] {
+			_, ok := Default.messages[normalize(locale)][key]
+			assert.True(t, ok, "%s has no message for %s", locale, key)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "email phải là địa chỉ email hợp lệ", Format("vi", "validation.user.email", ""))
+	assert.Equal(t, "age phải có kiểu int", Format("vi", "validation.type", "", "age", "int"))
+	assert.Equal(t, "no 7", Format("vi", "unknown", "no %d", 7))
+}
+
+func TestNegotiate(t *testing.T) {
+	request := func(target, acceptLanguage string, cookie *http.Cookie) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept-Language", acceptLanguage)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		return r
+	}
+
+	assert.Equal(t, "vi", Negotiate(request("/", "fr, vi-VN;q=0.8, en;q=0.5", nil), DefaultLocale))
+	assert.Equal(t, "en", Negotiate(request("/", "fr", nil), DefaultLocale))
+	assert.Equal(t, "vi", Negotiate(request("/", "", nil), "vi"))
+	// The choice of the user takes precedence over the browser languages
+	assert.Equal(t, "vi", Negotiate(request("/", "en", &http.Cookie{Name: PreferenceKey, Value: "vi"}), DefaultLocale))
+	assert.Equal(t, "en", Negotiate(request("/?lang=en", "vi", &http.Cookie{Name: PreferenceKey, Value: "vi"}), DefaultLocale))
+
+	r := request("/", "vi", nil)
+	assert.Equal(t, "vi", RequestLocale(r))
+	assert.Equal(t, "en", RequestLocale(r.WithContext(WithLocale(r.Context(), "en"))))
+}
+
+func TestAcceptedLanguages(t *testing.T) {
+	assert.Equal(t, []string{"vi-VN", "en"}, AcceptedLanguages("en;q=0.5, vi-VN, *;q=0.1, fr;q=0"))
+	assert.Empty(t, AcceptedLanguages(""))
+}
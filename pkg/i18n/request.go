@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PreferenceKey is the query parameter and cookie holding the locale chosen by the user,
+// which takes precedence over Accept-Language
+const PreferenceKey = "lang"
+
+// contextKey is the context key of the locale negotiated for a request
+type contextKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale carried by ctx
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	locale, ok := ctx.Value(contextKey{}).(string)
+	return locale, ok
+}
+
+// RequestLocale returns the locale negotiated for r by the locale middleware,
+// or negotiates it when the middleware did not run
+func RequestLocale(r *http.Request) string {
+	if r == nil {
+		return DefaultLocale
+	}
+	if locale, ok := FromContext(r.Context()); ok {
+		return locale
+	}
+	return Negotiate(r, DefaultLocale)
+}
+
+// Negotiate returns the locale of the Default catalog preferred by r: the one chosen by the user
+// in the lang query parameter or cookie, then the Accept-Language header, and fallback otherwise
+func Negotiate(r *http.Request, fallback string) string {
+	var preferences []string
+	if lang := r.URL.Query().Get(PreferenceKey); lang != "" {
+		preferences = append(preferences, lang)
+	}
+	if cookie, err := r.Cookie(PreferenceKey); err == nil && cookie.Value != "" {
+		preferences = append(preferences, cookie.Value)
+	}
+	preferences = append(preferences, AcceptedLanguages(r.Header.Get("Accept-Language"))...)
+
+	if locale, ok := Default.Match(preferences...); ok {
+		return locale
+	}
+	return fallback
+}
+
+// AcceptedLanguages returns the languages of an Accept-Language header by decreasing preference,
+// leaving out the wildcard and the languages with a zero quality
+func AcceptedLanguages(header string) []string {
+	type accepted struct {
+		language string
+		quality  float64
+	}
+
+	var languages []accepted
+	for _, part := range strings.Split(header, ",") {
+		language, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if language == "" || language == "*" || quality <= 0 {
+			continue
+		}
+		languages = append(languages, accepted{strings.TrimSpace(language), quality})
+	}
+	slices.SortStableFunc(languages, func(a, b accepted) int { return cmp.Compare(b.quality, a.quality) })
+
+	names := make([]string, 0, len(languages))
+	for _, language := range languages {
+		names = append(names, language.language)
+	}
+	return names
+}
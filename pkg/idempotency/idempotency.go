@@ -0,0 +1,137 @@
+// Package idempotency stores the outcome of mutating requests so client retries can be replayed
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// keyPrefix namespaces idempotency records in Redis
+const keyPrefix = "idempotency:"
+
+// Record states
+const (
+	StateProcessing = "processing"
+	StateCompleted  = "completed"
+)
+
+// Record is the stored state of a request made with an idempotency key
+type Record struct {
+	State       string    `json:"state"`
+	Fingerprint string    `json:"fingerprint"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Fingerprint identifies a request by method, path and body so a reused key with another payload is detected
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Store persists idempotency records
+type Store interface {
+	// Acquire claims key for a new request. When the key is already taken the existing record is returned with false.
+	Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, bool, error)
+	// Complete stores the final response for key
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release forgets key so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// RedisStore is a Store shared by every instance through Redis
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a Redis backed store
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Acquire claims key with a processing record that expires after lockTTL
+func (s *RedisStore) Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	record := &Record{
+		State:       StateProcessing,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	// Retry once in case the existing record expires between SETNX and GET
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.client.SetNX(ctx, keyPrefix+key, data, lockTTL).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+		}
+		if acquired {
+			return record, true, nil
+		}
+
+		existing, err := s.get(ctx, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil {
+			return existing, false, nil
+		}
+	}
+
+	return nil, false, fmt.Errorf("failed to acquire idempotency key %q", key)
+}
+
+// Complete replaces the processing record with the final response
+func (s *RedisStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	record.State = StateCompleted
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	if err := s.client.Set(ctx, keyPrefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Release deletes the record for key
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// get loads the record for key, returning nil when it does not exist
+func (s *RedisStore) get(ctx context.Context, key string) (*Record, error) {
+	data, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+	return &record, nil
+}
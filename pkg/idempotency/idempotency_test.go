@@ -0,0 +1,79 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStore(client), server
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("POST", "/api/v1/examples", []byte(`{"name":"a"}`))
+
+	assert.Equal(t, base, Fingerprint("POST", "/api/v1/examples", []byte(`{"name":"a"}`)))
+	assert.NotEqual(t, base, Fingerprint("POST", "/api/v1/examples", []byte(`{"name":"b"}`)))
+	assert.NotEqual(t, base, Fingerprint("PUT", "/api/v1/examples", []byte(`{"name":"a"}`)))
+}
+
+func TestRedisStoreLifecycle(t *testing.T) {
+	store, server := newTestStore(t)
+	ctx := context.Background()
+
+	record, acquired, err := store.Acquire(ctx, "key-1", "fp-1", 30*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, StateProcessing, record.State)
+
+	// A second attempt sees the in-flight record
+	existing, acquired, err := store.Acquire(ctx, "key-1", "fp-1", 30*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, StateProcessing, existing.State)
+
+	record.StatusCode = 200
+	record.ContentType = "application/json"
+	record.Body = []byte(`{"meta":{"code":20000}}`)
+	require.NoError(t, store.Complete(ctx, "key-1", record, time.Hour))
+
+	existing, acquired, err = store.Acquire(ctx, "key-1", "fp-1", 30*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, StateCompleted, existing.State)
+	assert.Equal(t, 200, existing.StatusCode)
+	assert.JSONEq(t, `{"meta":{"code":20000}}`, string(existing.Body))
+
+	// Completed records expire after the configured window
+	server.FastForward(time.Hour + time.Second)
+	_, acquired, err = store.Acquire(ctx, "key-1", "fp-2", 30*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestRedisStoreRelease(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	_, acquired, err := store.Acquire(ctx, "key-2", "fp", 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	require.NoError(t, store.Release(ctx, "key-2"))
+
+	_, acquired, err = store.Acquire(ctx, "key-2", "fp", 30*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
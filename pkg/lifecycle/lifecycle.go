@@ -0,0 +1,156 @@
+// Package lifecycle starts application components in order and stops them in reverse order on shutdown
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds the whole shutdown when no timeout is configured
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Hook is a component taking part in the application lifecycle.
+// Either function may be nil.
+type Hook struct {
+	// Name identifies the component in errors
+	Name string
+	// OnStart starts the component; long running work must be started in a goroutine
+	OnStart func(ctx context.Context) error
+	// OnStop releases the component, returning before ctx expires
+	OnStop func(ctx context.Context) error
+}
+
+// Manager runs hooks in registration order on start and in reverse order on stop.
+// The application is reported ready between a successful Start and the beginning of Stop.
+type Manager struct {
+	mu              sync.Mutex
+	hooks           []Hook
+	started         int
+	stopped         bool
+	ready           atomic.Bool
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	done            chan struct{}
+	doneOnce        sync.Once
+	err             error
+}
+
+// NewManager creates a manager; drainDelay is how long readiness stays false before components are stopped
+func NewManager(shutdownTimeout, drainDelay time.Duration) *Manager {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	return &Manager{
+		shutdownTimeout: shutdownTimeout,
+		drainDelay:      drainDelay,
+		done:            make(chan struct{}),
+	}
+}
+
+// Append registers a hook; hooks must be appended before Start
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Ready reports whether the application should receive traffic
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Start runs every OnStart in order. If one fails, the hooks already started are stopped
+// and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				startErr := fmt.Errorf("failed to start %s: %w", hook.Name, err)
+				if stopErr := m.Stop(context.Background()); stopErr != nil {
+					return errors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+		}
+
+		m.mu.Lock()
+		m.started = i + 1
+		m.mu.Unlock()
+	}
+
+	m.ready.Store(true)
+	return nil
+}
+
+// Fail asks the application to shut down because a component failed after starting
+func (m *Manager) Fail(err error) {
+	m.doneOnce.Do(func() {
+		m.err = err
+		close(m.done)
+	})
+}
+
+// Wait blocks until SIGINT or SIGTERM is received, ctx is done or Fail is called.
+// It returns the error passed to Fail, if any.
+func (m *Manager) Wait(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case <-signals:
+		return nil
+	case <-ctx.Done():
+		return nil
+	case <-m.done:
+		return m.err
+	}
+}
+
+// Stop marks the application not ready, waits for the drain delay so load balancers stop routing to it,
+// then runs OnStop of every started hook in reverse order within the shutdown timeout.
+// A failing hook does not prevent the remaining ones from stopping; all errors are returned.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := m.hooks[:m.started]
+	m.mu.Unlock()
+
+	wasReady := m.ready.Swap(false)
+	if wasReady && m.drainDelay > 0 {
+		select {
+		case <-time.After(m.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		if err := hook.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordingHook(name string, events *[]string, startErr, stopErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return stopErr
+		},
+	}
+}
+
+func TestManagerOrder(t *testing.T) {
+	var events []string
+	m := NewManager(time.Second, 0)
+	m.Append(recordingHook("logger", &events, nil, nil))
+	m.Append(recordingHook("db", &events, nil, errors.New("close failed")))
+	m.Append(recordingHook("http", &events, nil, nil))
+
+	assert.False(t, m.Ready())
+	require.NoError(t, m.Start(context.Background()))
+	assert.True(t, m.Ready())
+
+	err := m.Stop(context.Background())
+	assert.ErrorContains(t, err, "failed to stop db")
+	assert.False(t, m.Ready())
+	assert.Equal(t, []string{
+		"start logger", "start db", "start http",
+		"stop http", "stop db", "stop logger",
+	}, events)
+
+	// Stopping twice is a no-op
+	assert.NoError(t, m.Stop(context.Background()))
+	assert.Len(t, events, 6)
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	var events []string
+	m := NewManager(time.Second, 0)
+	m.Append(recordingHook("db", &events, nil, nil))
+	m.Append(recordingHook("redis", &events, errors.New("unreachable"), nil))
+	m.Append(recordingHook("http", &events, nil, nil))
+
+	err := m.Start(context.Background())
+	assert.ErrorContains(t, err, "failed to start redis")
+	assert.False(t, m.Ready())
+	// Only the hooks that started are stopped
+	assert.Equal(t, []string{"start db", "start redis", "stop db"}, events)
+}
+
+func TestManagerReadinessDropsBeforeStop(t *testing.T) {
+	m := NewManager(time.Second, 10*time.Millisecond)
+	var readyDuringStop bool
+	m.Append(Hook{
+		Name: "http",
+		OnStop: func(ctx context.Context) error {
+			readyDuringStop = m.Ready()
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return nil
+		},
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+	assert.False(t, readyDuringStop)
+}
+
+func TestManagerWaitFail(t *testing.T) {
+	m := NewManager(time.Second, 0)
+	m.Fail(errors.New("server crashed"))
+	m.Fail(errors.New("ignored"))
+
+	assert.EqualError(t, m.Wait(context.Background()), "server crashed")
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the context key of the request-scoped logger
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l as its logger
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// WithLogContext returns a copy of ctx whose logger also carries the fields of lc,
+// such as the request, user and trace IDs
+func WithLogContext(ctx context.Context, lc *LogContext) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(lc.ToFields()...))
+}
+
+// FromContext returns the logger carried by ctx, falling back to the global Logger
+// for work not started by a request, such as startup and background jobs
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	if Logger == nil {
+		return zap.NewNop()
+	}
+	return Logger
+}
+
+// SugarFromContext returns the sugared form of the logger carried by ctx
+func SugarFromContext(ctx context.Context) *zap.SugaredLogger {
+	return FromContext(ctx).Sugar()
+}
+
+// ForComponent returns the logger carried by ctx named after component, such as ComponentService,
+// so its level can be overridden separately
+func ForComponent(ctx context.Context, component string) *zap.Logger {
+	return FromContext(ctx).Named(component)
+}
+
+// SugarForComponent returns the sugared form of the logger of component
+func SugarForComponent(ctx context.Context, component string) *zap.SugaredLogger {
+	return ForComponent(ctx, component).Sugar()
+}
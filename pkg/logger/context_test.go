@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	previous := Logger
+	t.Cleanup(func() { Logger = previous })
+
+	core, logs := observer.New(zap.DebugLevel)
+	Logger = zap.New(core)
+
+	// Without a request-scoped logger the global one is used
+	FromContext(context.Background()).Info("global")
+	// A nil context must not panic
+	FromContext(nil).Info("nil context")
+
+	ctx := WithLogContext(context.Background(), &LogContext{RequestID: "req-1", TraceID: "trace-1"})
+	ctx = WithLogContext(ctx, &LogContext{UserID: "user-1"})
+	SugarFromContext(ctx).Infof("scoped %d", 1)
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Empty(t, entries[0].Context)
+	assert.Empty(t, entries[1].Context)
+	assert.Equal(t, "scoped 1", entries[2].Message)
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req-1",
+		"trace_id":   "trace-1",
+		"user_id":    "user-1",
+	}, entries[2].ContextMap())
+
+	Logger = nil
+	assert.NotPanics(t, func() { FromContext(context.Background()).Info("dropped") })
+}
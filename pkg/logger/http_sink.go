@@ -0,0 +1,242 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTP sink defaults, used for zero values in HTTPSinkConfig
+const (
+	DefaultHTTPSinkBatchSize     = 100
+	DefaultHTTPSinkFlushInterval = time.Second
+	DefaultHTTPSinkQueueSize     = 1000
+	DefaultHTTPSinkRetryBackoff  = 500 * time.Millisecond
+	DefaultHTTPSinkTimeout       = 5 * time.Second
+)
+
+// HTTPSinkConfig defines where and how an HTTPSink ships entries
+type HTTPSinkConfig struct {
+	// URL receives every batch as a POST of a JSON array
+	URL     string
+	Headers map[string]string
+	// BatchSize entries are sent at once, a partial batch is sent every FlushInterval
+	BatchSize     int
+	FlushInterval time.Duration
+	// QueueSize entries wait to be sent; once full, logging blocks for at most EnqueueTimeout
+	// before the entry is dropped. Zero EnqueueTimeout drops without blocking.
+	QueueSize      int
+	EnqueueTimeout time.Duration
+	// MaxRetries retries a failed batch with an exponential backoff starting at RetryBackoff
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Client sends the batches, defaults to a client with DefaultHTTPSinkTimeout
+	Client *http.Client
+}
+
+// HTTPSink ships entries in batches to an HTTP endpoint from a background goroutine
+type HTTPSink struct {
+	config HTTPSinkConfig
+	queue  chan []byte
+	// flush asks the shipper to send everything queued and close the reply channel
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// NewHTTPSink creates a sink and starts shipping its entries, Close stops it
+func NewHTTPSink(sinkConfig HTTPSinkConfig) (*HTTPSink, error) {
+	target, err := url.Parse(sinkConfig.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid log http sink url %q", sinkConfig.URL)
+	}
+	if sinkConfig.BatchSize <= 0 {
+		sinkConfig.BatchSize = DefaultHTTPSinkBatchSize
+	}
+	if sinkConfig.FlushInterval <= 0 {
+		sinkConfig.FlushInterval = DefaultHTTPSinkFlushInterval
+	}
+	if sinkConfig.QueueSize <= 0 {
+		sinkConfig.QueueSize = DefaultHTTPSinkQueueSize
+	}
+	if sinkConfig.RetryBackoff <= 0 {
+		sinkConfig.RetryBackoff = DefaultHTTPSinkRetryBackoff
+	}
+	if sinkConfig.Client == nil {
+		sinkConfig.Client = &http.Client{}
+	}
+	if sinkConfig.Client.Timeout <= 0 {
+		client := *sinkConfig.Client
+		client.Timeout = DefaultHTTPSinkTimeout
+		sinkConfig.Client = &client
+	}
+
+	s := &HTTPSink{
+		config:  sinkConfig,
+		queue:   make(chan []byte, sinkConfig.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full or a batch kept failing
+func (s *HTTPSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// WriteEntry implements Sink. Dropped entries are counted rather than reported,
+// as reporting them would log even more under load.
+func (s *HTTPSink) WriteEntry(_ zapcore.Entry, p []byte) error {
+	entry := bytes.Clone(bytes.TrimRight(p, "\n"))
+
+	select {
+	case <-s.done:
+		s.dropped.Add(1)
+		return nil
+	case s.queue <- entry:
+		return nil
+	default:
+	}
+
+	if s.config.EnqueueTimeout <= 0 {
+		s.dropped.Add(1)
+		return nil
+	}
+	timer := time.NewTimer(s.config.EnqueueTimeout)
+	defer timer.Stop()
+	select {
+	case s.queue <- entry:
+	case <-timer.C:
+		s.dropped.Add(1)
+	case <-s.done:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Sync implements Sink, sending every queued entry
+func (s *HTTPSink) Sync() error {
+	reply := make(chan struct{})
+	select {
+	case s.flush <- reply:
+		<-reply
+	case <-s.stopped:
+	}
+	return nil
+}
+
+// Close implements Sink, sending every queued entry before stopping
+func (s *HTTPSink) Close() error {
+	s.once.Do(func() { close(s.done) })
+	<-s.stopped
+	return nil
+}
+
+// run batches queued entries until the sink is closed
+func (s *HTTPSink) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.config.BatchSize)
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= s.config.BatchSize {
+				batch = s.send(batch)
+			}
+		case <-ticker.C:
+			batch = s.send(batch)
+		case reply := <-s.flush:
+			batch = s.send(s.drain(batch))
+			close(reply)
+		case <-s.done:
+			s.send(s.drain(batch))
+			return
+		}
+	}
+}
+
+// drain appends every entry waiting in the queue to batch
+func (s *HTTPSink) drain(batch [][]byte) [][]byte {
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+}
+
+// send posts entries in batches of at most BatchSize and returns entries emptied for reuse
+func (s *HTTPSink) send(entries [][]byte) [][]byte {
+	for start := 0; start < len(entries); start += s.config.BatchSize {
+		s.sendBatch(entries[start:min(start+s.config.BatchSize, len(entries))])
+	}
+	return entries[:0]
+}
+
+// sendBatch posts one batch, retrying failures that may be transient
+func (s *HTTPSink) sendBatch(batch [][]byte) {
+	body := make([]byte, 0, 2+len(batch)*256)
+	body = append(body, '[')
+	body = append(body, bytes.Join(batch, []byte{','})...)
+	body = append(body, ']')
+
+	backoff := s.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= s.config.MaxRetries {
+			s.dropped.Add(uint64(len(batch)))
+			// The sink cannot log its own failures, report them the way zap reports write errors
+			fmt.Fprintf(os.Stderr, "%v log http sink dropped %d entries: %v\n", time.Now(), len(batch), err)
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends body once, reporting whether a failure may succeed when retried
+func (s *HTTPSink) post(body []byte) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range s.config.Headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := s.config.Client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+	return retry, fmt.Errorf("unexpected status %d", response.StatusCode)
+}
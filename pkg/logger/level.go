@@ -0,0 +1,277 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ComponentField is the field naming the component a logger belongs to, as set by LogContext.WithComponent.
+// Loggers created with (*zap.Logger).Named are matched by their name as well.
+const ComponentField = "component"
+
+// Components of the application layers, whose loggers are named after them with ForComponent.
+// They are the names per-component level overrides accept, besides those of LogContext.WithComponent.
+const (
+	ComponentHTTP       = "http"
+	ComponentService    = "service"
+	ComponentRepository = "repository"
+)
+
+// LevelController changes the minimum log level at runtime, globally and per component.
+// Changes made with a duration revert once it elapses; the global level also follows
+// the configured level whenever no temporary change is active.
+type LevelController struct {
+	mu         sync.Mutex
+	configured zapcore.Level
+	global     zap.AtomicLevel
+	// globalExpiry is zero unless a temporary global change is active
+	globalExpiry time.Time
+	globalTimer  *time.Timer
+	// components is replaced on every change so logging reads it without locking
+	components atomic.Pointer[map[string]zapcore.Level]
+	overrides  map[string]*componentOverride
+}
+
+// componentOverride is the level set for one component and the timer reverting it
+type componentOverride struct {
+	level     zapcore.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// LevelState is a snapshot of the levels in effect
+type LevelState struct {
+	Level      string                    `json:"level"`
+	Configured string                    `json:"configured"`
+	ExpiresAt  *time.Time                `json:"expires_at,omitempty"`
+	Components map[string]ComponentLevel `json:"components,omitempty"`
+}
+
+// ComponentLevel is the level in effect for one component
+type ComponentLevel struct {
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewLevelController creates a controller starting at the configured level
+func NewLevelController(configured zapcore.Level) *LevelController {
+	l := &LevelController{
+		configured: configured,
+		global:     zap.NewAtomicLevelAt(configured),
+		overrides:  make(map[string]*componentOverride),
+	}
+	l.components.Store(&map[string]zapcore.Level{})
+	return l
+}
+
+// ParseLevel parses a level name, rejecting unknown names unlike ParseLogLevel
+func ParseLevel(level string) (zapcore.Level, error) {
+	var parsed zapcore.Level
+	if err := parsed.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return parsed, fmt.Errorf("unknown log level %q", level)
+	}
+	return parsed, nil
+}
+
+// Level returns the global level in effect
+func (l *LevelController) Level() zapcore.Level {
+	return l.global.Level()
+}
+
+// SetConfigured records a new configured level, applying it unless a temporary global change is active
+func (l *LevelController) SetConfigured(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.configured = level
+	if l.globalTimer == nil {
+		l.global.SetLevel(level)
+	}
+}
+
+// SetLevel changes the global level. A positive ttl reverts it to the configured level once elapsed,
+// otherwise the change lasts until the configured level changes.
+func (l *LevelController) SetLevel(level zapcore.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopGlobalTimer()
+	l.global.SetLevel(level)
+	if ttl > 0 {
+		l.globalExpiry = time.Now().Add(ttl)
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			// Ignore a timer replaced by a later change
+			if l.globalTimer == timer {
+				l.globalTimer = nil
+				l.globalExpiry = time.Time{}
+				l.global.SetLevel(l.configured)
+			}
+		})
+		l.globalTimer = timer
+	}
+}
+
+// ResetLevel reverts the global level to the configured one
+func (l *LevelController) ResetLevel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopGlobalTimer()
+	l.global.SetLevel(l.configured)
+}
+
+// SetComponentLevel overrides the level of one component. A positive ttl removes the override once elapsed.
+func (l *LevelController) SetComponentLevel(component string, level zapcore.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if previous, ok := l.overrides[component]; ok && previous.timer != nil {
+		previous.timer.Stop()
+	}
+
+	override := &componentOverride{level: level}
+	if ttl > 0 {
+		override.expiresAt = time.Now().Add(ttl)
+		override.timer = time.AfterFunc(ttl, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			// Ignore a timer replaced by a later change
+			if l.overrides[component] == override {
+				delete(l.overrides, component)
+				l.publishComponents()
+			}
+		})
+	}
+	l.overrides[component] = override
+	l.publishComponents()
+}
+
+// ResetComponentLevel removes the override of one component so it follows the global level again
+func (l *LevelController) ResetComponentLevel(component string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if override, ok := l.overrides[component]; ok {
+		if override.timer != nil {
+			override.timer.Stop()
+		}
+		delete(l.overrides, component)
+		l.publishComponents()
+	}
+}
+
+// Enabled reports whether an entry of lvl logged by component passes the levels in effect
+func (l *LevelController) Enabled(component string, lvl zapcore.Level) bool {
+	if component != "" {
+		if level, ok := (*l.components.Load())[component]; ok {
+			return lvl >= level
+		}
+	}
+	return l.global.Enabled(lvl)
+}
+
+// minLevel returns the most verbose level in effect for any component
+func (l *LevelController) minLevel() zapcore.Level {
+	minimum := l.global.Level()
+	for _, level := range *l.components.Load() {
+		minimum = min(minimum, level)
+	}
+	return minimum
+}
+
+// State returns a snapshot of the levels in effect
+func (l *LevelController) State() LevelState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := LevelState{
+		Level:      l.global.Level().String(),
+		Configured: l.configured.String(),
+		ExpiresAt:  expiry(l.globalExpiry),
+	}
+	if len(l.overrides) > 0 {
+		state.Components = make(map[string]ComponentLevel, len(l.overrides))
+		for component, override := range l.overrides {
+			state.Components[component] = ComponentLevel{
+				Level:     override.level.String(),
+				ExpiresAt: expiry(override.expiresAt),
+			}
+		}
+	}
+	return state
+}
+
+// stopGlobalTimer cancels a pending global revert; l.mu must be held
+func (l *LevelController) stopGlobalTimer() {
+	if l.globalTimer != nil {
+		l.globalTimer.Stop()
+		l.globalTimer = nil
+	}
+	l.globalExpiry = time.Time{}
+}
+
+// publishComponents replaces the component levels read by Enabled; l.mu must be held
+func (l *LevelController) publishComponents() {
+	components := make(map[string]zapcore.Level, len(l.overrides))
+	for component, override := range l.overrides {
+		components[component] = override.level
+	}
+	l.components.Store(&components)
+}
+
+// expiry returns nil for the zero time so permanent changes omit expires_at
+func expiry(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// levelCore filters entries with a LevelController. Wrapped cores must enable every level.
+type levelCore struct {
+	zapcore.Core
+	levels    *LevelController
+	component string
+}
+
+// newLevelCore wraps core so entries are filtered by levels
+func newLevelCore(core zapcore.Core, levels *LevelController) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+// Enabled implements zapcore.LevelEnabler
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.levels.minLevel()
+}
+
+// With implements zapcore.Core, remembering the component field
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	component := c.component
+	for _, field := range fields {
+		if field.Key == ComponentField && field.Type == zapcore.StringType {
+			component = field.String
+		}
+	}
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels, component: component}
+}
+
+// Check implements zapcore.Core
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	component := c.component
+	if component == "" {
+		component = entry.LoggerName
+	}
+	if !c.levels.Enabled(component, entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
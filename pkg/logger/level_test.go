@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newLevelLogger(levels *LevelController) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(newLevelCore(core, levels)), logs
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, zapcore.WarnLevel, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLevelControllerGlobal(t *testing.T) {
+	levels := NewLevelController(zapcore.InfoLevel)
+	log, logs := newLevelLogger(levels)
+
+	log.Debug("dropped")
+	levels.SetLevel(zapcore.DebugLevel, 0)
+	log.Debug("kept")
+	assert.Equal(t, 1, logs.Len())
+
+	// A permanent change follows later configuration changes
+	levels.SetConfigured(zapcore.WarnLevel)
+	assert.Equal(t, zapcore.WarnLevel, levels.Level())
+
+	// A temporary change survives configuration changes until it expires
+	levels.SetLevel(zapcore.ErrorLevel, 50*time.Millisecond)
+	levels.SetConfigured(zapcore.InfoLevel)
+	state := levels.State()
+	assert.Equal(t, "error", state.Level)
+	assert.Equal(t, "info", state.Configured)
+	require.NotNil(t, state.ExpiresAt)
+
+	assert.Eventually(t, func() bool {
+		return levels.Level() == zapcore.InfoLevel
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, levels.State().ExpiresAt)
+
+	levels.SetLevel(zapcore.ErrorLevel, time.Hour)
+	levels.ResetLevel()
+	assert.Equal(t, zapcore.InfoLevel, levels.Level())
+}
+
+func TestLevelControllerComponents(t *testing.T) {
+	levels := NewLevelController(zapcore.InfoLevel)
+	log, logs := newLevelLogger(levels)
+	repoLog := log.With(zap.String(ComponentField, "repo"))
+	namedLog := log.Named("cache")
+
+	levels.SetComponentLevel("repo", zapcore.DebugLevel, 50*time.Millisecond)
+	levels.SetComponentLevel("cache", zapcore.ErrorLevel, 0)
+
+	repoLog.Debug("repo debug")
+	log.Debug("global debug")
+	namedLog.Warn("cache warn")
+	namedLog.Error("cache error")
+
+	messages := make([]string, 0)
+	for _, entry := range logs.TakeAll() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"repo debug", "cache error"}, messages)
+	assert.Len(t, levels.State().Components, 2)
+
+	// The temporary override reverts to the global level
+	assert.Eventually(t, func() bool {
+		return !levels.Enabled("repo", zapcore.DebugLevel)
+	}, time.Second, 10*time.Millisecond)
+
+	levels.ResetComponentLevel("cache")
+	namedLog.Warn("cache warn")
+	assert.Equal(t, 1, logs.Len())
+	assert.Empty(t, levels.State().Components)
+}
+
+func TestComponentLoggers(t *testing.T) {
+	levels := NewLevelController(zapcore.InfoLevel)
+	ring := NewRingBuffer(10)
+	appLogger, err := New(WithConsole(false), WithLevels(levels), WithSink(ring))
+	require.NoError(t, err)
+	ctx := NewContext(context.Background(), appLogger.Zap().With(zap.String("request_id", "req-1")))
+
+	levels.SetComponentLevel(ComponentService, zapcore.DebugLevel, 0)
+	ForComponent(ctx, ComponentHTTP).Debug("http debug")
+	SugarForComponent(ctx, ComponentService).Debugf("service %s", "debug")
+	ForComponent(ctx, ComponentRepository).Debug("repository debug")
+	ForComponent(ctx, ComponentRepository).Info("repository info")
+
+	entries := ring.Entries(zapcore.DebugLevel, 10)
+	require.Len(t, entries, 2)
+	assert.Contains(t, string(entries[0]), `"logger":"service"`)
+	assert.Contains(t, string(entries[0]), `"msg":"service debug"`)
+	assert.Contains(t, string(entries[0]), `"request_id":"req-1"`)
+	assert.Contains(t, string(entries[1]), `"logger":"repository"`)
+}
@@ -2,10 +2,12 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -13,6 +15,7 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/ntdat104/go-clean-architecture/pkg/redact"
 	"github.com/ntdat104/go-clean-architecture/util"
 )
 
@@ -20,8 +23,13 @@ import (
 var (
 	Logger        *zap.Logger
 	SugaredLogger *zap.SugaredLogger
+	// Levels changes the level of the global logger at runtime
+	Levels *LevelController
 )
 
+// watchLevelOnce registers the configured level watcher only once however often Init runs
+var watchLevelOnce sync.Once
+
 // LogContext holds contextual information for structured logging
 type LogContext struct {
 	RequestID string
@@ -122,6 +130,14 @@ type Options struct {
 	EnableStacktrace bool
 	// File configuration, required if EnableFile is true
 	FileConfig *FileConfig
+	// Redactor masks sensitive fields before they are written, nil disables masking
+	Redactor *redact.Redactor
+	// Levels filters entries at runtime instead of the static Level when set
+	Levels *LevelController
+	// Sampling limits how often one message is logged, nil writes every entry
+	Sampling *SamplingConfig
+	// Sinks receive every entry encoded as JSON, besides the console and file outputs
+	Sinks []Sink
 }
 
 // FileConfig defines the configuration for log files
@@ -231,6 +247,34 @@ func WithStacktrace(enable bool) Option {
 	}
 }
 
+// WithRedactor masks sensitive fields such as passwords and tokens in every entry
+func WithRedactor(redactor *redact.Redactor) Option {
+	return func(o *Options) {
+		o.Redactor = redactor
+	}
+}
+
+// WithLevels lets levels change the log level at runtime, globally and per component
+func WithLevels(levels *LevelController) Option {
+	return func(o *Options) {
+		o.Levels = levels
+	}
+}
+
+// WithSampling limits how often one message is logged, dropping repeated entries
+func WithSampling(samplingConfig *SamplingConfig) Option {
+	return func(o *Options) {
+		o.Sampling = samplingConfig
+	}
+}
+
+// WithSink adds a destination receiving every entry encoded as JSON, closed by (*AppLogger).Close
+func WithSink(sink Sink) Option {
+	return func(o *Options) {
+		o.Sinks = append(o.Sinks, sink)
+	}
+}
+
 // FileConfigFromGlobal creates a FileConfig from global configuration
 func FileConfigFromGlobal() *FileConfig {
 	return &FileConfig{
@@ -265,7 +309,18 @@ func New(opts ...Option) (*AppLogger, error) {
 	zapOptions := logger.buildZapOptions()
 
 	// Create zap logger
-	zapLogger := zap.New(zapcore.NewTee(cores...), zapOptions...)
+	core := zapcore.NewTee(cores...)
+	if options.Redactor != nil {
+		core = redact.NewCore(core, options.Redactor)
+	}
+	// Sample below the level core so entries filtered by level do not count as logged
+	if options.Sampling != nil {
+		core = newSamplingCore(core, options.Sampling)
+	}
+	if options.Levels != nil {
+		core = newLevelCore(core, options.Levels)
+	}
+	zapLogger := zap.New(core, zapOptions...)
 	logger.zap = zapLogger
 	logger.sugar = zapLogger.Sugar()
 
@@ -282,7 +337,7 @@ func (l *AppLogger) buildCores() []zapcore.Core {
 		consoleCore := zapcore.NewCore(
 			consoleEncoder,
 			zapcore.AddSync(os.Stdout),
-			zap.NewAtomicLevelAt(l.options.Level),
+			l.levelEnabler(),
 		)
 		cores = append(cores, consoleCore)
 	}
@@ -308,14 +363,28 @@ func (l *AppLogger) buildCores() []zapcore.Core {
 		fileCore := zapcore.NewCore(
 			fileEncoder,
 			zapcore.AddSync(lum), // Temporary output to stdout, actual output handled by hook
-			zap.NewAtomicLevelAt(l.options.Level),
+			l.levelEnabler(),
 		)
 		cores = append(cores, fileCore)
 	}
 
+	// Add the additional sinks
+	for _, sink := range l.options.Sinks {
+		cores = append(cores, newSinkCore(sink, zapcore.NewJSONEncoder(l.getJSONEncoderConfig()), l.levelEnabler()))
+	}
+
 	return cores
 }
 
+// levelEnabler returns the level of the output cores. With runtime levels the cores accept
+// every entry and the level core filters them instead.
+func (l *AppLogger) levelEnabler() zapcore.LevelEnabler {
+	if l.options.Levels != nil {
+		return zapcore.DebugLevel
+	}
+	return zap.NewAtomicLevelAt(l.options.Level)
+}
+
 // getJSONEncoderConfig gets the encoder configuration
 func (l *AppLogger) getJSONEncoderConfig() zapcore.EncoderConfig {
 	encoderConfig := zapcore.EncoderConfig{
@@ -399,9 +468,9 @@ func (l *AppLogger) Sync() error {
 	return l.zap.Sync()
 }
 
-// Close closes the logger and synchronizes the buffer
+// Close synchronizes the buffer and closes the sinks of the logger
 func (l *AppLogger) Close() error {
-	return l.Sync()
+	return errors.Join(l.Sync(), closeSinks(l.options.Sinks))
 }
 
 // Init initializes the global logger instances (for compatibility with existing code)
@@ -419,6 +488,17 @@ func Init() {
 	}
 	opts = append(opts, WithLevel(logLevel))
 
+	// Let the level change at runtime, following the configuration file and the admin endpoint
+	Levels = NewLevelController(logLevel)
+	opts = append(opts, WithLevels(Levels))
+	watchLevelOnce.Do(func() {
+		config.Subscribe(func(change config.Change) {
+			if change.Changed("log.level") && change.New.Log.Level != "" && Levels != nil {
+				Levels.SetConfigured(ParseLogLevel(change.New.Log.Level))
+			}
+		})
+	})
+
 	// Configure console output (defaults to true if not specified)
 	enableConsole := true
 	if config.GlobalConfig.Log != nil {
@@ -447,6 +527,35 @@ func Init() {
 	}
 	opts = append(opts, WithStacktrace(enableStacktrace))
 
+	// Mask sensitive fields using the configured redaction rules
+	if config.GlobalConfig.Log != nil {
+		opts = append(opts, WithRedactor(redact.New(config.GlobalConfig.Log.Redaction)))
+	}
+
+	// Drop repeated entries under load
+	samplingConfig, err := SamplingConfigFromGlobal()
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	if samplingConfig != nil {
+		opts = append(opts, WithSampling(samplingConfig))
+	}
+
+	// Ship entries to the additional sinks, keeping the ring buffer for the admin endpoint
+	sinks, err := SinksFromGlobal()
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	var ring *RingBuffer
+	for _, sink := range sinks {
+		opts = append(opts, WithSink(sink))
+		if buffer, ok := sink.(*RingBuffer); ok {
+			ring = buffer
+		}
+	}
+
 	// Add file output if global config has log file settings
 	if config.GlobalConfig.Log != nil && config.GlobalConfig.Log.SavePath != "" {
 		opts = append(opts, WithFile(FileConfigFromGlobal()))
@@ -459,9 +568,14 @@ func Init() {
 		os.Exit(1)
 	}
 
-	// Set global variables
+	// Set global variables, closing the sinks of a previous global logger
 	Logger = logger.Zap()
 	SugaredLogger = logger.Sugar()
+	Ring = ring
+	if err := CloseSinks(); err != nil {
+		Logger.Warn("Failed to close previous log sinks", zap.Error(err))
+	}
+	globalSinks = sinks
 
 	// Register deferred sync
 	// Direct Sync() call is commented out because it might cause errors on program shutdown
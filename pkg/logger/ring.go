@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRingSize is the number of entries kept by a ring buffer when no size is configured
+const DefaultRingSize = 1000
+
+// RingBuffer keeps the most recent entries in memory, overwriting the oldest once full
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []ringEntry
+	next    int
+	count   int
+}
+
+// ringEntry is one JSON encoded entry and its level
+type ringEntry struct {
+	level zapcore.Level
+	data  json.RawMessage
+}
+
+var _ Sink = (*RingBuffer)(nil)
+
+// NewRingBuffer creates a ring buffer keeping the last size entries
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+	return &RingBuffer{entries: make([]ringEntry, size)}
+}
+
+// WriteEntry implements Sink
+func (r *RingBuffer) WriteEntry(entry zapcore.Entry, p []byte) error {
+	data := bytes.Clone(bytes.TrimRight(p, "\n"))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = ringEntry{level: entry.Level, data: data}
+	r.next = (r.next + 1) % len(r.entries)
+	r.count = min(r.count+1, len(r.entries))
+	return nil
+}
+
+// Entries returns up to limit of the most recent entries at level or above, oldest first.
+// A limit of zero or less returns every matching entry.
+func (r *RingBuffer) Entries(level zapcore.Level, limit int) []json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]json.RawMessage, 0)
+	// Walk backwards from the newest entry, then restore the chronological order
+	for i := 0; i < r.count && (limit <= 0 || len(entries) < limit); i++ {
+		entry := r.entries[(r.next-1-i+len(r.entries))%len(r.entries)]
+		if entry.level >= level {
+			entries = append(entries, entry.data)
+		}
+	}
+	slices.Reverse(entries)
+	return entries
+}
+
+// Sync implements Sink
+func (r *RingBuffer) Sync() error {
+	return nil
+}
+
+// Close implements Sink
+func (r *RingBuffer) Close() error {
+	return nil
+}
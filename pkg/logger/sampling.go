@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+// DefaultSamplingTick is the sampling interval used when none is configured
+const DefaultSamplingTick = time.Second
+
+// DroppedEntries counts the entries dropped by sampling in every logger created by New
+var DroppedEntries = &DroppedCounter{}
+
+// SamplingConfig defines how often one message is logged. Within every Tick the first Initial
+// entries with the same level and message are written, then every Thereafter-th.
+type SamplingConfig struct {
+	Tick       time.Duration
+	Initial    int
+	Thereafter int
+	// Levels overrides the rule of single levels
+	Levels map[zapcore.Level]SamplingRule
+	// Dropped counts the dropped entries, defaults to DroppedEntries
+	Dropped *DroppedCounter
+}
+
+// SamplingRule overrides the sampling of one level, Disabled writes every entry of the level
+type SamplingRule struct {
+	Initial    int
+	Thereafter int
+	Disabled   bool
+}
+
+// SamplingConfigFromGlobal creates a SamplingConfig from global configuration, nil when sampling is disabled
+func SamplingConfigFromGlobal() (*SamplingConfig, error) {
+	if config.GlobalConfig.Log == nil || config.GlobalConfig.Log.Sampling == nil || !config.GlobalConfig.Log.Sampling.Enabled {
+		return nil, nil
+	}
+	sampling := config.GlobalConfig.Log.Sampling
+
+	samplingConfig := &SamplingConfig{
+		Tick:       config.GetDuration(sampling.Tick),
+		Initial:    sampling.Initial,
+		Thereafter: sampling.Thereafter,
+		Levels:     make(map[zapcore.Level]SamplingRule, len(sampling.Levels)),
+	}
+	for name, rule := range sampling.Levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log.sampling.levels entry: %w", err)
+		}
+		samplingConfig.Levels[level] = SamplingRule{
+			Initial:    rule.Initial,
+			Thereafter: rule.Thereafter,
+			Disabled:   rule.Disabled,
+		}
+	}
+	return samplingConfig, nil
+}
+
+// DroppedCounter counts the entries dropped by sampling per level
+type DroppedCounter struct {
+	counts [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+}
+
+// hook implements the zapcore.SamplerHook signature
+func (d *DroppedCounter) hook(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+	if decision&zapcore.LogDropped == 0 || entry.Level < zapcore.DebugLevel || entry.Level > zapcore.FatalLevel {
+		return
+	}
+	d.counts[entry.Level-zapcore.DebugLevel].Add(1)
+}
+
+// Count returns the number of dropped entries of level
+func (d *DroppedCounter) Count(level zapcore.Level) uint64 {
+	if level < zapcore.DebugLevel || level > zapcore.FatalLevel {
+		return 0
+	}
+	return d.counts[level-zapcore.DebugLevel].Load()
+}
+
+// Snapshot returns the number of dropped entries per level name, including levels without drops
+func (d *DroppedCounter) Snapshot() map[string]uint64 {
+	snapshot := make(map[string]uint64, len(d.counts))
+	for level := zapcore.DebugLevel; level <= zapcore.FatalLevel; level++ {
+		snapshot[level.String()] = d.Count(level)
+	}
+	return snapshot
+}
+
+// samplingCore samples entries with the sampler of their level. A nil sampler writes
+// every entry of the level to the wrapped core.
+type samplingCore struct {
+	zapcore.Core
+	fallback zapcore.Core
+	levels   map[zapcore.Level]zapcore.Core
+}
+
+// newSamplingCore wraps core so entries are sampled as configured by samplingConfig
+func newSamplingCore(core zapcore.Core, samplingConfig *SamplingConfig) zapcore.Core {
+	tick := samplingConfig.Tick
+	if tick <= 0 {
+		tick = DefaultSamplingTick
+	}
+	dropped := samplingConfig.Dropped
+	if dropped == nil {
+		dropped = DroppedEntries
+	}
+	sampler := func(initial, thereafter int) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, tick, initial, thereafter, zapcore.SamplerHook(dropped.hook))
+	}
+
+	sampling := &samplingCore{
+		Core:     core,
+		fallback: sampler(samplingConfig.Initial, samplingConfig.Thereafter),
+		levels:   make(map[zapcore.Level]zapcore.Core, len(samplingConfig.Levels)),
+	}
+	for level, rule := range samplingConfig.Levels {
+		if rule.Disabled {
+			sampling.levels[level] = nil
+			continue
+		}
+		sampling.levels[level] = sampler(rule.Initial, rule.Thereafter)
+	}
+	return sampling
+}
+
+// With implements zapcore.Core. The samplers keep sharing their counters with the parent.
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	levels := make(map[zapcore.Level]zapcore.Core, len(c.levels))
+	for level, sampler := range c.levels {
+		if sampler != nil {
+			sampler = sampler.With(fields)
+		}
+		levels[level] = sampler
+	}
+	return &samplingCore{
+		Core:     c.Core.With(fields),
+		fallback: c.fallback.With(fields),
+		levels:   levels,
+	}
+}
+
+// Check implements zapcore.Core
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	sampler, ok := c.levels[entry.Level]
+	if !ok {
+		sampler = c.fallback
+	}
+	if sampler == nil {
+		return c.Core.Check(entry, checked)
+	}
+	return sampler.Check(entry, checked)
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+func newSamplingLogger(samplingConfig *SamplingConfig) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(newSamplingCore(core, samplingConfig)), logs
+}
+
+func TestSamplingFirstThenEvery(t *testing.T) {
+	dropped := &DroppedCounter{}
+	log, logs := newSamplingLogger(&SamplingConfig{Tick: time.Minute, Initial: 2, Thereafter: 3, Dropped: dropped})
+
+	for range 8 {
+		log.Info("hot")
+	}
+	log.Info("other")
+
+	// Entries 1, 2 and 5, 8 of "hot" are kept, messages are sampled separately
+	assert.Equal(t, 4, logs.FilterMessage("hot").Len())
+	assert.Equal(t, 1, logs.FilterMessage("other").Len())
+	assert.Equal(t, uint64(4), dropped.Count(zapcore.InfoLevel))
+}
+
+func TestSamplingLevelOverrides(t *testing.T) {
+	dropped := &DroppedCounter{}
+	log, logs := newSamplingLogger(&SamplingConfig{
+		Tick:    time.Minute,
+		Initial: 1,
+		Levels: map[zapcore.Level]SamplingRule{
+			zapcore.DebugLevel: {Initial: 2},
+			zapcore.ErrorLevel: {Disabled: true},
+		},
+		Dropped: dropped,
+	})
+
+	for range 5 {
+		log.Debug("hot")
+		log.Info("hot")
+		log.Error("hot")
+	}
+
+	assert.Equal(t, 2, logs.FilterLevelExact(zapcore.DebugLevel).Len())
+	assert.Equal(t, 1, logs.FilterLevelExact(zapcore.InfoLevel).Len())
+	assert.Equal(t, 5, logs.FilterLevelExact(zapcore.ErrorLevel).Len())
+
+	snapshot := dropped.Snapshot()
+	assert.Equal(t, uint64(3), snapshot["debug"])
+	assert.Equal(t, uint64(4), snapshot["info"])
+	assert.Equal(t, uint64(0), snapshot["error"])
+}
+
+func TestSamplingSharesCountersWithChildren(t *testing.T) {
+	log, logs := newSamplingLogger(&SamplingConfig{
+		Tick:    time.Minute,
+		Initial: 1,
+		Levels:  map[zapcore.Level]SamplingRule{zapcore.WarnLevel: {Disabled: true}},
+		Dropped: &DroppedCounter{},
+	})
+
+	log.With(zap.String("request_id", "a")).Info("hot")
+	log.With(zap.String("request_id", "b")).Info("hot")
+	log.With(zap.String("request_id", "c")).Warn("hot")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, "c", entries[1].ContextMap()["request_id"])
+}
+
+func TestSamplingBelowLevelFilter(t *testing.T) {
+	dropped := &DroppedCounter{}
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := NewLevelController(zapcore.InfoLevel)
+	log := zap.New(newLevelCore(newSamplingCore(core, &SamplingConfig{Tick: time.Minute, Initial: 1, Dropped: dropped}), levels))
+
+	// Entries filtered by level neither use up the initial budget nor count as dropped
+	log.Debug("hot")
+	log.Debug("hot")
+	levels.SetLevel(zapcore.DebugLevel, 0)
+	log.Debug("hot")
+
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, uint64(0), dropped.Count(zapcore.DebugLevel))
+}
+
+func TestSamplingConfigFromGlobal(t *testing.T) {
+	previous := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = previous })
+
+	config.GlobalConfig = &config.Config{Log: &config.LogConfig{}}
+	samplingConfig, err := SamplingConfigFromGlobal()
+	require.NoError(t, err)
+	assert.Nil(t, samplingConfig)
+
+	config.GlobalConfig.Log.Sampling = &config.SamplingConfig{
+		Enabled:    true,
+		Tick:       "2s",
+		Initial:    10,
+		Thereafter: 5,
+		Levels:     map[string]config.SamplingRule{"error": {Disabled: true}},
+	}
+	samplingConfig, err = SamplingConfigFromGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, samplingConfig.Tick)
+	assert.Equal(t, 10, samplingConfig.Initial)
+	assert.Equal(t, 5, samplingConfig.Thereafter)
+	assert.Equal(t, SamplingRule{Disabled: true}, samplingConfig.Levels[zapcore.ErrorLevel])
+
+	config.GlobalConfig.Log.Sampling.Levels = map[string]config.SamplingRule{"verbose": {}}
+	_, err = SamplingConfigFromGlobal()
+	assert.Error(t, err)
+}
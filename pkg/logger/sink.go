@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+// Sink is an additional destination of log entries besides the console and file outputs
+type Sink interface {
+	// WriteEntry writes one entry encoded as a JSON line; p is reused once WriteEntry returns
+	WriteEntry(entry zapcore.Entry, p []byte) error
+	// Sync flushes buffered entries
+	Sync() error
+	// Close flushes buffered entries and releases the sink
+	Close() error
+}
+
+// Ring is the in-memory ring buffer of the global logger, nil when disabled
+var Ring *RingBuffer
+
+// globalSinks are the sinks of the global logger, closed by CloseSinks
+var globalSinks []Sink
+
+// SinksFromGlobal creates the sinks enabled in global configuration
+func SinksFromGlobal() ([]Sink, error) {
+	if config.GlobalConfig.Log == nil || config.GlobalConfig.Log.Sinks == nil {
+		return nil, nil
+	}
+	sinksConfig := config.GlobalConfig.Log.Sinks
+
+	var sinks []Sink
+	if syslog := sinksConfig.Syslog; syslog != nil && syslog.Enabled {
+		appName := syslog.AppName
+		if appName == "" && config.GlobalConfig.App != nil {
+			appName = config.GlobalConfig.App.Name
+		}
+		sink, err := NewSyslogSink(syslog.Network, syslog.Address, appName, syslog.Facility)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if shipper := sinksConfig.HTTP; shipper != nil && shipper.Enabled {
+		sink, err := NewHTTPSink(HTTPSinkConfig{
+			URL:            shipper.URL,
+			Headers:        shipper.Headers,
+			BatchSize:      shipper.BatchSize,
+			FlushInterval:  config.GetDuration(shipper.FlushInterval),
+			QueueSize:      shipper.QueueSize,
+			EnqueueTimeout: config.GetDuration(shipper.EnqueueTimeout),
+			MaxRetries:     shipper.MaxRetries,
+			RetryBackoff:   config.GetDuration(shipper.RetryBackoff),
+			Client:         &http.Client{Timeout: config.GetDuration(shipper.Timeout)},
+		})
+		if err != nil {
+			return nil, errors.Join(err, closeSinks(sinks))
+		}
+		sinks = append(sinks, sink)
+	}
+	if ring := sinksConfig.Ring; ring != nil && ring.Enabled {
+		sinks = append(sinks, NewRingBuffer(ring.Size))
+	}
+	return sinks, nil
+}
+
+// CloseSinks flushes and closes the sinks of the global logger
+func CloseSinks() error {
+	err := closeSinks(globalSinks)
+	globalSinks = nil
+	return err
+}
+
+// closeSinks closes every sink, returning the joined errors
+func closeSinks(sinks []Sink) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close log sink: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sinkCore encodes entries enabled by its level and writes them to a sink
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	sink    Sink
+}
+
+// newSinkCore creates a core writing entries encoded by encoder to sink
+func newSinkCore(sink Sink, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &sinkCore{LevelEnabler: enabler, encoder: encoder, sink: sink}
+}
+
+// With implements zapcore.Core
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &sinkCore{LevelEnabler: c.LevelEnabler, encoder: encoder, sink: c.sink}
+}
+
+// Check implements zapcore.Core
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	return c.sink.WriteEntry(entry, buf.Bytes())
+}
+
+// Sync implements zapcore.Core
+func (c *sinkCore) Sync() error {
+	return c.sink.Sync()
+}
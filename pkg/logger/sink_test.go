@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogHeader matches an RFC 5424 header followed by the JSON message
+var syslogHeader = regexp.MustCompile(`^<(\d+)>1 \S+ \S+ (\S+) \d+ - - (\{.*\})$`)
+
+func newSinkLogger(t *testing.T, sink Sink) *zap.Logger {
+	t.Helper()
+	appLogger, err := New(WithConsole(false), WithColor(false), WithLevel(zapcore.DebugLevel), WithSink(sink))
+	require.NoError(t, err)
+	return appLogger.Zap()
+}
+
+func TestRingBuffer(t *testing.T) {
+	ring := NewRingBuffer(3)
+	log := newSinkLogger(t, ring)
+
+	log.Debug("first")
+	log.Info("second", zap.String("key", "value"))
+	log.Warn("third")
+	log.Error("fourth")
+
+	// The oldest entry was overwritten
+	entries := ring.Entries(zapcore.DebugLevel, 0)
+	require.Len(t, entries, 3)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(entries[0], &entry))
+	assert.Equal(t, "second", entry["msg"])
+	assert.Equal(t, "value", entry["key"])
+
+	entries = ring.Entries(zapcore.WarnLevel, 1)
+	require.Len(t, entries, 1)
+	assert.Contains(t, string(entries[0]), `"fourth"`)
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "my app", 16)
+	require.NoError(t, err)
+	defer sink.Close()
+	newSinkLogger(t, sink).Warn("disk almost full", zap.Int("percent", 95))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	match := syslogHeader.FindStringSubmatch(string(buf[:n]))
+	require.NotNil(t, match, string(buf[:n]))
+	// local0 (16) * 8 + warning (4)
+	assert.Equal(t, "132", match[1])
+	assert.Equal(t, "my-app", match[2])
+	assert.Contains(t, match[3], `"percent":95`)
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	messages := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			// Octet counting: the message length, a space, then the message
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, size)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				return
+			}
+			messages <- string(message)
+		}
+	}()
+
+	sink, err := NewSyslogSink("tcp", listener.Addr().String(), "app", 1)
+	require.NoError(t, err)
+	defer sink.Close()
+	log := newSinkLogger(t, sink)
+	log.Error("first")
+	log.Info("second")
+
+	for _, expected := range []struct{ priority, message string }{{"11", "first"}, {"14", "second"}} {
+		select {
+		case message := <-messages:
+			match := syslogHeader.FindStringSubmatch(message)
+			require.NotNil(t, match, message)
+			assert.Equal(t, expected.priority, match[1])
+			assert.Contains(t, match[3], expected.message)
+		case <-time.After(5 * time.Second):
+			t.Fatal("syslog message not received")
+		}
+	}
+}
+
+func TestSyslogSinkDoesNotBlock(t *testing.T) {
+	// The server is stuck: connections are queued by the kernel but never accepted nor read
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sink, err := NewSyslogSink("tcp", listener.Addr().String(), "app", 1)
+	require.NoError(t, err)
+	sink.timeout = 100 * time.Millisecond
+	log := newSinkLogger(t, sink)
+
+	large := strings.Repeat("x", 16*1024)
+	start := time.Now()
+	for range 3 * DefaultSyslogQueueSize {
+		log.Info(large)
+	}
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// Once the socket buffers are full the writes time out and entries are dropped
+	assert.Eventually(t, func() bool {
+		return sink.Dropped() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	start = time.Now()
+	require.NoError(t, sink.Close())
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNewSyslogSinkValidates(t *testing.T) {
+	_, err := NewSyslogSink("unix", "/dev/log", "app", 1)
+	assert.Error(t, err)
+	_, err = NewSyslogSink("udp", "", "app", 1)
+	assert.Error(t, err)
+	_, err = NewSyslogSink("udp", "127.0.0.1:514", "app", 24)
+	assert.Error(t, err)
+}
+
+func TestHTTPSinkBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var batch []map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{
+		URL:           server.URL,
+		Headers:       map[string]string{"X-Api-Key": "secret"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	log := newSinkLogger(t, sink)
+	for _, message := range []string{"one", "two", "three"} {
+		log.Info(message)
+	}
+	// Close sends the partial batch
+	require.NoError(t, sink.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Equal(t, "one", batches[0][0]["msg"])
+	require.Len(t, batches[1], 1)
+	assert.Equal(t, "three", batches[1][0]["msg"])
+	assert.Zero(t, sink.Dropped())
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	newSinkLogger(t, sink).Info("retried")
+	require.NoError(t, sink.Sync())
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Zero(t, sink.Dropped())
+
+	// Client errors are not retried
+	attempts.Store(10)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	newSinkLogger(t, sink).Info("rejected")
+	require.NoError(t, sink.Sync())
+	assert.Equal(t, int32(11), attempts.Load())
+	assert.Equal(t, uint64(1), sink.Dropped())
+}
+
+func TestHTTPSinkBackPressure(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{
+		URL:            server.URL,
+		BatchSize:      1,
+		QueueSize:      1,
+		EnqueueTimeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	// The first entry is in flight, the second waits in the queue, the rest are dropped once
+	// the enqueue timeout elapses instead of blocking logging forever
+	log := newSinkLogger(t, sink)
+	log.Info("in flight")
+	require.Eventually(t, func() bool { return len(sink.queue) == 0 }, 5*time.Second, time.Millisecond)
+	start := time.Now()
+	for range 4 {
+		log.Info("queued or dropped")
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	assert.Equal(t, uint64(3), sink.Dropped())
+
+	close(release)
+	require.NoError(t, sink.Close())
+	assert.Equal(t, uint64(3), sink.Dropped())
+}
+
+func TestNewHTTPSinkValidatesURL(t *testing.T) {
+	_, err := NewHTTPSink(HTTPSinkConfig{URL: "localhost:9880"})
+	assert.Error(t, err)
+}
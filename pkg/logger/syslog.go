@@ -0,0 +1,270 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Syslog defaults and limits of RFC 5424
+const (
+	DefaultSyslogNetwork = "udp"
+	// DefaultSyslogQueueSize entries wait to be sent; later ones are dropped
+	DefaultSyslogQueueSize = 1000
+	// syslogTimeout bounds connecting to the server and writing one message
+	syslogTimeout = 5 * time.Second
+	// syslogBackoff is the first pause in sending after a failure, doubled up to syslogMaxBackoff
+	syslogBackoff    = 100 * time.Millisecond
+	syslogMaxBackoff = 30 * time.Second
+	syslogNilValue   = "-"
+	maxSyslogApp     = 48
+	maxSyslogHost    = 255
+	syslogTimestamp  = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// SyslogSink sends entries as RFC 5424 messages to a syslog server from a background goroutine, so a slow
+// or unreachable server never blocks logging. The connection is opened on the first entry and reopened after
+// a failed write; entries are dropped while the queue is full and, after a failure, until the next attempt.
+// TCP messages are framed by octet counting (RFC 6587).
+type SyslogSink struct {
+	network  string
+	address  string
+	appName  string
+	hostname string
+	facility int
+	pid      int
+	timeout  time.Duration
+
+	queue chan []byte
+	// flush asks the writer to send everything queued and close the reply channel
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+
+	// conn, backoff and retryAt belong to the writer goroutine
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+}
+
+var _ Sink = (*SyslogSink)(nil)
+
+// NewSyslogSink creates a sink sending to address over network, "udp" or "tcp", and starts its writer; Close stops it
+func NewSyslogSink(network, address, appName string, facility int) (*SyslogSink, error) {
+	if network == "" {
+		network = DefaultSyslogNetwork
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network %q", network)
+	}
+	if address == "" {
+		return nil, errors.New("syslog address is required")
+	}
+	if facility < 0 || facility > 23 {
+		return nil, fmt.Errorf("syslog facility must be between 0 and 23, got %d", facility)
+	}
+
+	hostname, _ := os.Hostname()
+	s := &SyslogSink{
+		network:  network,
+		address:  address,
+		appName:  syslogField(appName, maxSyslogApp),
+		hostname: syslogField(hostname, maxSyslogHost),
+		facility: facility,
+		pid:      os.Getpid(),
+		timeout:  syslogTimeout,
+		queue:    make(chan []byte, DefaultSyslogQueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full or the server unreachable
+func (s *SyslogSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// WriteEntry implements Sink, queueing the message without blocking. Dropped entries are counted
+// rather than reported, as reporting them would log even more.
+func (s *SyslogSink) WriteEntry(entry zapcore.Entry, p []byte) error {
+	message := s.format(entry, p)
+
+	select {
+	case <-s.done:
+		s.dropped.Add(1)
+	case s.queue <- message:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Sync implements Sink, sending every queued entry
+func (s *SyslogSink) Sync() error {
+	reply := make(chan struct{})
+	select {
+	case s.flush <- reply:
+		<-reply
+	case <-s.stopped:
+	}
+	return nil
+}
+
+// Close implements Sink, sending every queued entry before closing the connection
+func (s *SyslogSink) Close() error {
+	s.once.Do(func() { close(s.done) })
+	<-s.stopped
+	return nil
+}
+
+// run sends queued messages until the sink is closed
+func (s *SyslogSink) run() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case message := <-s.queue:
+			s.send(message)
+		case reply := <-s.flush:
+			s.drain()
+			close(reply)
+		case <-s.done:
+			s.drain()
+			s.closeConn()
+			return
+		}
+	}
+}
+
+// drain sends every message waiting in the queue
+func (s *SyslogSink) drain() {
+	for {
+		select {
+		case message := <-s.queue:
+			s.send(message)
+		default:
+			return
+		}
+	}
+}
+
+// send writes one message, dropping it while backing off after a failure
+func (s *SyslogSink) send(message []byte) {
+	if time.Now().Before(s.retryAt) {
+		s.dropped.Add(1)
+		return
+	}
+
+	// Retry once on a new connection, the server may have restarted since the last entry
+	connected := s.conn != nil
+	err := s.write(message)
+	if err != nil && connected {
+		s.closeConn()
+		err = s.write(message)
+	}
+	if err == nil {
+		s.backoff, s.retryAt = 0, time.Time{}
+		return
+	}
+
+	s.closeConn()
+	s.dropped.Add(1)
+	if s.backoff == 0 {
+		// The sink cannot log its own failures, report them the way zap reports write errors
+		fmt.Fprintf(os.Stderr, "%v log syslog sink is dropping entries: %v\n", time.Now(), err)
+	}
+	s.backoff = min(max(2*s.backoff, syslogBackoff), syslogMaxBackoff)
+	s.retryAt = time.Now().Add(s.backoff)
+}
+
+// format builds the RFC 5424 message of an entry, with the JSON encoded entry as message
+func (s *SyslogSink) format(entry zapcore.Entry, p []byte) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "<%d>1 %s %s %s %d - - ",
+		s.facility*8+syslogSeverity(entry.Level),
+		entry.Time.Format(syslogTimestamp),
+		s.hostname,
+		s.appName,
+		s.pid,
+	)
+	message.Write(bytes.TrimRight(p, "\n"))
+
+	if s.network == "tcp" {
+		return append([]byte(fmt.Sprintf("%d ", message.Len())), message.Bytes()...)
+	}
+	return message.Bytes()
+}
+
+// write sends one message, connecting first when needed
+func (s *SyslogSink) write(message []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(message)
+	return err
+}
+
+// closeConn closes the connection so the next write reconnects
+func (s *SyslogSink) closeConn() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// syslogSeverity maps a zap level to a syslog severity
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// syslogField makes value a valid header field: printable ASCII without spaces, at most limit long
+func syslogField(value string, limit int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '-'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return syslogNilValue
+	}
+	if len(value) > limit {
+		value = value[:limit]
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// logDroppedCollector exports the number of log entries dropped by sampling on every scrape
+type logDroppedCollector struct {
+	dropped func() map[string]uint64
+	desc    *prometheus.Desc
+}
+
+// NewLogDroppedCollector creates a collector exporting the dropped entries per level returned by dropped,
+// typically (*logger.DroppedCounter).Snapshot
+func NewLogDroppedCollector(dropped func() map[string]uint64) prometheus.Collector {
+	return &logDroppedCollector{
+		dropped: dropped,
+		desc: prometheus.NewDesc("log_entries_dropped_total",
+			"Total number of log entries dropped by sampling", []string{"level"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *logDroppedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *logDroppedCollector) Collect(ch chan<- prometheus.Metric) {
+	for level, count := range c.dropped() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(count), level)
+	}
+}
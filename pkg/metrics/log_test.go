@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogDroppedCollector(t *testing.T) {
+	collector := NewLogDroppedCollector(func() map[string]uint64 {
+		return map[string]uint64{"debug": 3, "info": 1}
+	})
+
+	expected := `
+# HELP log_entries_dropped_total Total number of log entries dropped by sampling
+# TYPE log_entries_dropped_total counter
+log_entries_dropped_total{level="debug"} 3
+log_entries_dropped_total{level="info"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
@@ -3,17 +3,33 @@ package metrics
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// DefaultPath is the path metrics are served on when none is configured
+const DefaultPath = "/metrics"
+
+// Exposition modes selectable in metrics_server.mode
+const (
+	// ModeServer serves metrics on a dedicated listener
+	ModeServer = "server"
+	// ModeRouter serves metrics on the main HTTP router
+	ModeRouter = "router"
+)
+
 var (
 	registry    = prometheus.NewRegistry()
+	handler     = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	initialized = false
 	mutex       sync.Mutex
 
@@ -42,18 +58,67 @@ var (
 	DomainEventTotal *prometheus.CounterVec
 )
 
+// Options defines the configuration options for metrics collection
+type Options struct {
+	// Histogram buckets of HTTP request durations, in seconds
+	HTTPBuckets []float64
+	// Histogram buckets of database query and transaction durations, in seconds
+	DBBuckets []float64
+}
+
+// DefaultOptions returns the default configuration options
+func DefaultOptions() *Options {
+	return &Options{
+		HTTPBuckets: prometheus.DefBuckets,
+		DBBuckets:   prometheus.DefBuckets,
+	}
+}
+
+// Option defines a function type for configuring options
+type Option func(*Options)
+
+// WithHTTPBuckets sets the HTTP request duration buckets, empty keeps the defaults
+func WithHTTPBuckets(buckets []float64) Option {
+	return func(o *Options) {
+		if len(buckets) > 0 {
+			o.HTTPBuckets = buckets
+		}
+	}
+}
+
+// WithDBBuckets sets the database query and transaction duration buckets, empty keeps the defaults
+func WithDBBuckets(buckets []float64) Option {
+	return func(o *Options) {
+		if len(buckets) > 0 {
+			o.DBBuckets = buckets
+		}
+	}
+}
+
 // Initialized returns whether metrics has been initialized
 func Initialized() bool {
 	return initialized
 }
 
-// Init initializes the metrics collection system
-func Init() {
+// Init initializes the metrics collection system, together with the Go runtime and process collectors.
+// Calls after the first one are no-ops.
+func Init(opts ...Option) error {
 	mutex.Lock()
 	defer mutex.Unlock()
 
 	if initialized {
-		return
+		return nil
+	}
+
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	if err := validateBuckets(options.HTTPBuckets); err != nil {
+		return fmt.Errorf("invalid HTTP buckets: %w", err)
+	}
+	if err := validateBuckets(options.DBBuckets); err != nil {
+		return fmt.Errorf("invalid DB buckets: %w", err)
 	}
 
 	// HTTP metrics
@@ -61,7 +126,7 @@ func Init() {
 		prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
 			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
+			Buckets: options.HTTPBuckets,
 		},
 		[]string{"handler", "method", "status"},
 	)
@@ -97,7 +162,7 @@ func Init() {
 		prometheus.HistogramOpts{
 			Name:    "db_query_duration_seconds",
 			Help:    "Duration of database queries",
-			Buckets: prometheus.DefBuckets,
+			Buckets: options.DBBuckets,
 		},
 		[]string{"db", "operation"},
 	)
@@ -106,7 +171,7 @@ func Init() {
 		prometheus.HistogramOpts{
 			Name:    "transaction_duration_seconds",
 			Help:    "Duration of transactions",
-			Buckets: prometheus.DefBuckets,
+			Buckets: options.DBBuckets,
 		},
 		[]string{"store_type"},
 	)
@@ -130,6 +195,8 @@ func Init() {
 
 	// Register all metrics
 	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		RequestDuration,
 		RequestTotal,
 		ErrorTotal,
@@ -141,44 +208,79 @@ func Init() {
 	)
 
 	initialized = true
+	return nil
+}
+
+// validateBuckets checks buckets are strictly increasing, as histograms require
+func validateBuckets(buckets []float64) error {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return fmt.Errorf("bucket %v must be greater than %v", buckets[i], buckets[i-1])
+		}
+	}
+	return nil
+}
+
+// Register adds a collector to the metrics registry
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector)
+}
+
+// Handler returns the handler exposing the metrics registry for Prometheus scraping
+func Handler() http.Handler {
+	return handler
 }
 
 // ServeHTTP serves the metrics endpoint for Prometheus scraping
 func ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	handler.ServeHTTP(w, r)
 }
 
 // StartServer starts a metrics server on the given address
 func StartServer(ctx context.Context, addr string) error {
+	server := NewServer(addr, DefaultPath, nil)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Metrics server started on %s\n", addr)
+	return server.ListenAndServe()
+}
+
+// NewServer creates the metrics server exposing metrics on path (DefaultPath when empty), /health and /ready
+// without starting it. /ready answers 503 while ready reports false; a nil ready function is always ready.
+func NewServer(addr, path string, ready func() bool) *http.Server {
 	if !initialized {
 		Init()
 	}
+	if path == "" {
+		path = DefaultPath
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/metrics", ServeHTTP)
+	mux.Handle(path, handler)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if ready != nil && !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("Not Ready"))
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("Ready"))
 	})
 
-	server := &http.Server{
+	return &http.Server{
 		Addr:    addr,
 		Handler: mux,
 	}
-
-	go func() {
-		<-ctx.Done()
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		server.Shutdown(shutdownCtx)
-	}()
-
-	fmt.Printf("Metrics server started on %s\n", addr)
-	return server.ListenAndServe()
 }
 
 // HTTPMiddleware creates a middleware for measuring HTTP request metrics
@@ -194,15 +296,32 @@ func HTTPMiddleware(handler string) func(http.Handler) http.Handler {
 			rw := NewResponseWriter(w)
 			next.ServeHTTP(rw, r)
 
-			duration := time.Since(start).Seconds()
-			status := fmt.Sprintf("%d", rw.Status())
-			RequestDuration.WithLabelValues(handler, r.Method, status).Observe(duration)
-			RequestTotal.WithLabelValues(handler, r.Method, status).Inc()
+			ObserveHTTPRequest(handler, r.Method, rw.Status(), time.Since(start))
 		})
 	}
 }
 
-// MeasureDBQuery measures the duration of a database query
+// ObserveHTTPRequest records the duration and outcome of an HTTP request; 4xx and 5xx
+// responses are also counted as client and server errors of handler
+func ObserveHTTPRequest(handler, method string, status int, duration time.Duration) {
+	if !initialized {
+		return
+	}
+
+	statusCode := strconv.Itoa(status)
+	RequestDuration.WithLabelValues(handler, method, statusCode).Observe(duration.Seconds())
+	RequestTotal.WithLabelValues(handler, method, statusCode).Inc()
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		ErrorTotal.WithLabelValues("server_error", handler).Inc()
+	case status >= http.StatusBadRequest:
+		ErrorTotal.WithLabelValues("client_error", handler).Inc()
+	}
+}
+
+// MeasureDBQuery measures the duration of a database query. sql.ErrNoRows is a result
+// rather than a failure and is not counted as an error.
 func MeasureDBQuery(db, operation string, f func() error) error {
 	if !initialized {
 		return f()
@@ -213,7 +332,7 @@ func MeasureDBQuery(db, operation string, f func() error) error {
 	duration := time.Since(start).Seconds()
 
 	DBQueryDuration.WithLabelValues(db, operation).Observe(duration)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		ErrorTotal.WithLabelValues("db", db).Inc()
 	}
 
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBuckets(t *testing.T) {
+	assert.NoError(t, validateBuckets(nil))
+	assert.NoError(t, validateBuckets([]float64{0.1, 0.5, 1}))
+	assert.Error(t, validateBuckets([]float64{0.1, 1, 0.5}))
+	assert.Error(t, validateBuckets([]float64{1, 1}))
+}
+
+func TestNewServerPath(t *testing.T) {
+	require.NoError(t, Init())
+	ready := false
+	server := NewServer(":0", "/internal/metrics", func() bool { return ready })
+
+	get := func(path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		body, _ := io.ReadAll(recorder.Body)
+		return recorder.Code, string(body)
+	}
+
+	code, body := get("/internal/metrics")
+	assert.Equal(t, http.StatusOK, code)
+	// Runtime and process collectors are registered with the application metrics
+	assert.Contains(t, body, "go_goroutines")
+	assert.Contains(t, body, "process_")
+
+	code, _ = get("/metrics")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = get("/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	ready = true
+	code, _ = get("/ready")
+	assert.Equal(t, http.StatusOK, code)
+}
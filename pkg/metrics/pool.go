@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbStatsCollector exports sql.DBStats of one database on every scrape
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+// NewDBStatsCollector creates a collector exporting the connection pool returned by stats,
+// typically (*sql.DB).Stats, with a db label set to name
+func NewDBStatsCollector(name string, stats func() sql.DBStats) prometheus.Collector {
+	labels := prometheus.Labels{"db": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+metric, help, nil, labels)
+	}
+
+	return &dbStatsCollector{
+		stats: stats,
+
+		maxOpen:           desc("max_open_connections", "Maximum number of open database connections"),
+		open:              desc("open_connections", "Number of established database connections"),
+		inUse:             desc("in_use_connections", "Number of database connections in use"),
+		idle:              desc("idle_connections", "Number of idle database connections"),
+		waitCount:         desc("wait_count_total", "Total number of connections waited for"),
+		waitDuration:      desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+		maxIdleClosed:     desc("max_idle_closed_total", "Total number of connections closed due to the idle connection limit"),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Total number of connections closed due to the idle time limit"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Total number of connections closed due to the connection lifetime limit"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+// Collect implements prometheus.Collector
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+// redisPoolCollector exports redis.PoolStats of one client on every scrape
+type redisPoolCollector struct {
+	stats func() *redis.PoolStats
+
+	hits     *prometheus.Desc
+	misses   *prometheus.Desc
+	timeouts *prometheus.Desc
+	total    *prometheus.Desc
+	idle     *prometheus.Desc
+	stale    *prometheus.Desc
+}
+
+// NewRedisPoolCollector creates a collector exporting the connection pool returned by stats,
+// typically (*redis.Client).PoolStats, with a client label set to name
+func NewRedisPoolCollector(name string, stats func() *redis.PoolStats) prometheus.Collector {
+	labels := prometheus.Labels{"client": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+metric, help, nil, labels)
+	}
+
+	return &redisPoolCollector{
+		stats: stats,
+
+		hits:     desc("hits_total", "Total number of times a free connection was found in the pool"),
+		misses:   desc("misses_total", "Total number of times a free connection was not found in the pool"),
+		timeouts: desc("timeouts_total", "Total number of times waiting for a connection timed out"),
+		total:    desc("total_connections", "Number of connections in the pool"),
+		idle:     desc("idle_connections", "Number of idle connections in the pool"),
+		stale:    desc("stale_connections_total", "Total number of stale connections removed from the pool"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.stale
+}
+
+// Collect implements prometheus.Collector
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	if stats == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBStatsCollector(t *testing.T) {
+	collector := NewDBStatsCollector("mysql", func() sql.DBStats {
+		return sql.DBStats{
+			MaxOpenConnections: 10,
+			OpenConnections:    4,
+			InUse:              3,
+			Idle:               1,
+			WaitCount:          7,
+			WaitDuration:       1500 * time.Millisecond,
+		}
+	})
+
+	expected := `
+# HELP db_pool_in_use_connections Number of database connections in use
+# TYPE db_pool_in_use_connections gauge
+db_pool_in_use_connections{db="mysql"} 3
+# HELP db_pool_wait_count_total Total number of connections waited for
+# TYPE db_pool_wait_count_total counter
+db_pool_wait_count_total{db="mysql"} 7
+# HELP db_pool_wait_duration_seconds_total Total time blocked waiting for a new connection
+# TYPE db_pool_wait_duration_seconds_total counter
+db_pool_wait_duration_seconds_total{db="mysql"} 1.5
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"db_pool_in_use_connections", "db_pool_wait_count_total", "db_pool_wait_duration_seconds_total"))
+	assert.Equal(t, 9, testutil.CollectAndCount(collector))
+}
+
+func TestRedisPoolCollector(t *testing.T) {
+	stats := &redis.PoolStats{Hits: 12, Misses: 2, Timeouts: 1, TotalConns: 5, IdleConns: 4}
+	collector := NewRedisPoolCollector("cache", func() *redis.PoolStats { return stats })
+
+	expected := `
+# HELP redis_pool_hits_total Total number of times a free connection was found in the pool
+# TYPE redis_pool_hits_total counter
+redis_pool_hits_total{client="cache"} 12
+# HELP redis_pool_timeouts_total Total number of times waiting for a connection timed out
+# TYPE redis_pool_timeouts_total counter
+redis_pool_timeouts_total{client="cache"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"redis_pool_hits_total", "redis_pool_timeouts_total"))
+	assert.Equal(t, 6, testutil.CollectAndCount(collector))
+
+	stats = nil
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+func TestMeasureDBQueryIgnoresNoRows(t *testing.T) {
+	Init()
+
+	before := testutil.ToFloat64(ErrorTotal.WithLabelValues("db", "pool_test"))
+	err := MeasureDBQuery("pool_test", "get", func() error { return sql.ErrNoRows })
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, before, testutil.ToFloat64(ErrorTotal.WithLabelValues("db", "pool_test")))
+
+	err = MeasureDBQuery("pool_test", "get", func() error { return sql.ErrConnDone })
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Equal(t, before+1, testutil.ToFloat64(ErrorTotal.WithLabelValues("db", "pool_test")))
+}
@@ -0,0 +1,190 @@
+// Package openapi builds OpenAPI 3 documents from Go types and serves them with Swagger UI
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+// Defaults of the paths the document and Swagger UI are served at
+const (
+	DefaultPath   = "/openapi.json"
+	DefaultUIPath = "/docs"
+)
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups operations, such as the routes of a module
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation describes a route
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request by media type
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response by media type
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by the operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is a JSON schema as extended by OpenAPI 3.0. An empty schema accepts any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+}
+
+// Ref returns a schema referencing the component schema name
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Builder adds operations to a document, describing the Go types of their requests and
+// responses as schemas. Named struct types become component schemas.
+type Builder struct {
+	doc *Document
+	// names are the component names of the struct types described so far
+	names map[reflect.Type]string
+	// RuleDescription describes the binding rules the builder cannot express as constraints,
+	// such as custom validators; it returns false for the unknown ones
+	RuleDescription func(rule string) (string, bool)
+}
+
+// NewBuilder creates a builder of an empty document
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      make(map[string]*PathItem),
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// Document returns the document built so far
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+// AddTag adds a tag unless the document already has one with the same name
+func (b *Builder) AddTag(tag Tag) {
+	if !slices.ContainsFunc(b.doc.Tags, func(existing Tag) bool { return existing.Name == tag.Name }) {
+		b.doc.Tags = append(b.doc.Tags, tag)
+	}
+}
+
+// AddOperation adds op at the gin path pattern, e.g. /users/:uuid becomes /users/{uuid}.
+// Every parameter of the path missing from op is added as a required string.
+func (b *Builder) AddOperation(method, path string, op *Operation) {
+	path, names := PathTemplate(path)
+	for _, name := range names {
+		if !slices.ContainsFunc(op.Parameters, func(p *Parameter) bool { return p.In == "path" && p.Name == name }) {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// SetComponent sets the component schema name
+func (b *Builder) SetComponent(name string, schema *Schema) {
+	b.doc.Components.Schemas[name] = schema
+}
+
+// Component returns the component schema name, or nil
+func (b *Builder) Component(name string) *Schema {
+	return b.doc.Components.Schemas[name]
+}
+
+// pathParameter matches the parameters of gin path patterns, :name and the catch-all *name
+var pathParameter = regexp.MustCompile(`[:*]([^/]+)`)
+
+// PathTemplate converts a gin path pattern to an OpenAPI path template, returning the names of its parameters
+func PathTemplate(path string) (string, []string) {
+	var names []string
+	template := pathParameter.ReplaceAllStringFunc(path, func(parameter string) string {
+		names = append(names, parameter[1:])
+		return "{" + parameter[1:] + "}"
+	})
+	return template, names
+}
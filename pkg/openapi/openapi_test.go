@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createOrderReq struct {
+	Audit
+	ShopID   int               `uri:"shop_id" binding:"required"`
+	Expand   string            `form:"expand" binding:"omitempty,oneof=items customer"`
+	Name     string            `json:"name" binding:"required,min=3,max=64"`
+	Email    string            `json:"email" binding:"omitempty,email"`
+	Quantity int               `json:"quantity" binding:"gt=0,lte=100"`
+	Status   int               `json:"status" binding:"oneof=1 2"`
+	Tags     []string          `json:"tags" binding:"max=5,dive,min=2"`
+	Address  *address          `json:"address" binding:"required"`
+	Labels   map[string]string `json:"labels"`
+	Raw      json.RawMessage   `json:"raw"`
+	Code     string            `json:"code" binding:"required,code"`
+	Internal string            `json:"-"`
+	Untagged bool
+}
+
+type node struct {
+	Children []node `json:"children"`
+}
+
+func size(n uint64) *uint64 {
+	return &n
+}
+
+func number(n float64) *float64 {
+	return &n
+}
+
+func TestRequest(t *testing.T) {
+	builder := NewBuilder(Info{Title: "test", Version: "v1"})
+	builder.RuleDescription = func(rule string) (string, bool) {
+		return "must be a " + rule, rule == "code"
+	}
+
+	parameters, body := builder.Request(reflect.TypeOf(&createOrderReq{}))
+	assert.Equal(t, []*Parameter{
+		{Name: "shop_id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		{Name: "expand", In: "query", Schema: &Schema{Type: "string", Enum: []any{"items", "customer"}}},
+	}, parameters)
+	assert.Equal(t, Ref("openapi.createOrderReq"), body)
+
+	schema := builder.Component("openapi.createOrderReq")
+	require.NotNil(t, schema)
+	assert.Equal(t, []string{"name", "address", "code"}, schema.Required)
+	assert.NotContains(t, schema.Properties, "shop_id")
+	assert.NotContains(t, schema.Properties, "Internal")
+	assert.Equal(t, &Schema{Type: "boolean"}, schema.Properties["Untagged"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created_at"])
+	assert.Equal(t, &Schema{Type: "string", MinLength: size(3), MaxLength: size(64)}, schema.Properties["name"])
+	assert.Equal(t, &Schema{Type: "string", Format: "email"}, schema.Properties["email"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64", Minimum: number(0), ExclusiveMinimum: true, Maximum: number(100)}, schema.Properties["quantity"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64", Enum: []any{float64(1), float64(2)}}, schema.Properties["status"])
+	assert.Equal(t, &Schema{Type: "array", MaxItems: size(5), Items: &Schema{Type: "string", MinLength: size(2)}}, schema.Properties["tags"])
+	assert.Equal(t, Ref("openapi.address"), schema.Properties["address"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["labels"])
+	assert.Equal(t, &Schema{}, schema.Properties["raw"])
+	assert.Equal(t, "must be a code", schema.Properties["code"].Description)
+
+	assert.Equal(t, []string{"city"}, builder.Component("openapi.address").Required)
+}
+
+func TestRequestWithoutBody(t *testing.T) {
+	type getOrderReq struct {
+		ID int `uri:"id"`
+	}
+
+	parameters, body := NewBuilder(Info{}).Request(reflect.TypeOf(getOrderReq{}))
+	assert.Nil(t, body)
+	require.Len(t, parameters, 1)
+	assert.Equal(t, "id", parameters[0].Name)
+}
+
+func TestRecursiveSchema(t *testing.T) {
+	builder := NewBuilder(Info{})
+	assert.Equal(t, Ref("openapi.node"), builder.Schema(reflect.TypeOf(node{})))
+	assert.Equal(t, Ref("openapi.node"), builder.Component("openapi.node").Properties["children"].Items)
+}
+
+func TestAddOperation(t *testing.T) {
+	builder := NewBuilder(Info{})
+	builder.AddOperation(http.MethodGet, "/shops/:shop_id/orders/:id", &Operation{
+		Parameters: []*Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}},
+	})
+
+	op := (*builder.Document().Paths["/shops/{shop_id}/orders/{id}"])["get"]
+	require.NotNil(t, op)
+	assert.Equal(t, []*Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}},
+		{Name: "shop_id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+	}, op.Parameters)
+}
+
+func TestPathTemplate(t *testing.T) {
+	template, names := PathTemplate("/users/:uuid/files/*path")
+	assert.Equal(t, "/users/{uuid}/files/{path}", template)
+	assert.Equal(t, []string{"uuid", "path"}, names)
+}
+
+func TestUIHandler(t *testing.T) {
+	handler, err := UIHandler("Orders </title>", "/openapi.json", "https://assets.example.com/swagger/")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), `<title>Orders &lt;/title&gt;</title>`)
+	assert.Contains(t, recorder.Body.String(), `href="https://assets.example.com/swagger/swagger-ui.css"`)
+	assert.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+}
+
+func TestAssetsHandler(t *testing.T) {
+	handler := assetsHandler(fstest.MapFS{
+		"swagger-ui.css": {Data: []byte("body {}")},
+		"README.md":      {Data: []byte("# swagger-ui-dist")},
+	})
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	recorder := serve("/swagger-ui.css")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/css; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "body {}", recorder.Body.String())
+
+	// Only the files the page loads are served
+	assert.Equal(t, http.StatusNotFound, serve("/README.md").Code)
+	assert.Equal(t, http.StatusNotFound, serve("/swagger-ui-bundle.js").Code)
+}
+
+func TestVendoredAssets(t *testing.T) {
+	// Without them the Swagger UI page is blank unless openapi.ui_assets_url is set
+	assert.True(t, VendoredAssets(), "swagger-ui-dist %s is not vendored, run go generate ./pkg/openapi and commit the files", SwaggerUIVersion)
+}
@@ -0,0 +1,325 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// formats are the string formats of the binding rules checking one
+var formats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"http_url": "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// Schema describes values of t as encoding/json encodes them. Named struct types are added to the
+// components and referenced; the fields bound from the path or query only are left out.
+func (b *Builder) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return b.component(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// Request describes a request bound from t: the fields tagged uri are path parameters, those
+// tagged form query parameters and the others the JSON body, nil when there are none
+func (b *Builder) Request(t reflect.Type) ([]*Parameter, *Schema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, b.Schema(t)
+	}
+
+	var parameters []*Parameter
+	hasBody := false
+	eachField(t, func(field reflect.StructField) {
+		in, name := boundFrom(field)
+		if in == "" {
+			hasBody = true
+			return
+		}
+		schema, required := b.fieldSchema(field)
+		parameter := &Parameter{Name: name, In: in, Required: required || in == "path", Schema: schema}
+		// The descriptions of the rules belong to the parameter
+		parameter.Description, schema.Description = schema.Description, ""
+		parameters = append(parameters, parameter)
+	})
+
+	if !hasBody {
+		return parameters, nil
+	}
+	return parameters, b.Schema(t)
+}
+
+// component adds the schema of the named struct type t to the components, once, and references it
+func (b *Builder) component(t reflect.Type) *Schema {
+	if name, ok := b.names[t]; ok {
+		return Ref(name)
+	}
+
+	name := componentName(t)
+	for _, taken := range b.names {
+		if taken == name {
+			name = sanitize(t.PkgPath() + "." + t.Name())
+			break
+		}
+	}
+	// Registered before its fields are described, so recursive types reference themselves
+	b.names[t] = name
+	b.SetComponent(name, b.structSchema(t))
+	return Ref(name)
+}
+
+// structSchema describes the JSON object of the struct type t
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	eachField(t, func(field reflect.StructField) {
+		if in, _ := boundFrom(field); in != "" {
+			return
+		}
+		name := jsonName(field)
+		property, required := b.fieldSchema(field)
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	})
+	return schema
+}
+
+// fieldSchema describes field with the constraints of its binding rules, reporting whether it is required
+func (b *Builder) fieldSchema(field reflect.StructField) (*Schema, bool) {
+	schema := b.Schema(field.Type)
+	required := b.applyRules(schema, field.Type, field.Tag.Get("binding"))
+	return schema, required
+}
+
+// applyRules sets the constraints of the validator rules on schema, the rules after dive applying
+// to the items. Referenced schemas cannot hold constraints; they only get required.
+func (b *Builder) applyRules(schema *Schema, t reflect.Type, rules string) bool {
+	required := false
+	dived := false
+	var descriptions []string
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch {
+		case name == "" || name == "omitempty" || strings.Contains(name, "|"):
+			continue
+		case name == "required":
+			required = required || !dived
+			continue
+		case name == "dive":
+			if schema.Items == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+				return required
+			}
+			schema, t, dived = schema.Items, t.Elem(), true
+			continue
+		case schema.Ref != "":
+			continue
+		}
+
+		if format, ok := formats[name]; ok {
+			schema.Format = format
+			continue
+		}
+		switch name {
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				bound(schema, t.Kind(), name, value)
+			}
+		case "oneof":
+			schema.Enum = enum(t.Kind(), param)
+		default:
+			if b.RuleDescription != nil {
+				if description, ok := b.RuleDescription(name); ok {
+					descriptions = append(descriptions, description)
+				}
+			}
+		}
+	}
+
+	if len(descriptions) > 0 {
+		schema.Description = strings.Join(descriptions, "; ")
+	}
+	return required
+}
+
+// bound sets the constraint of a size rule: the length of strings, the number of items of lists,
+// and the value of numbers
+func bound(schema *Schema, kind reflect.Kind, rule string, value float64) {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array:
+		size := uint64(max(value, 0))
+		minimum, maximum := &schema.MinLength, &schema.MaxLength
+		if kind != reflect.String {
+			minimum, maximum = &schema.MinItems, &schema.MaxItems
+		}
+		switch rule {
+		case "min", "gte":
+			*minimum = &size
+		case "gt":
+			size++
+			*minimum = &size
+		case "max", "lte":
+			*maximum = &size
+		case "lt":
+			size = uint64(max(value-1, 0))
+			*maximum = &size
+		case "len":
+			*minimum, *maximum = &size, &size
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		switch rule {
+		case "min", "gte":
+			schema.Minimum = &value
+		case "gt":
+			schema.Minimum, schema.ExclusiveMinimum = &value, true
+		case "max", "lte":
+			schema.Maximum = &value
+		case "lt":
+			schema.Maximum, schema.ExclusiveMaximum = &value, true
+		case "len":
+			schema.Minimum, schema.Maximum = &value, &value
+		}
+	}
+}
+
+// enum returns the values of a oneof rule, numbers for numeric fields
+func enum(kind reflect.Kind, param string) []any {
+	var values []any
+	for _, value := range strings.Fields(param) {
+		if kind != reflect.String {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				values = append(values, number)
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// eachField calls fn with the exported fields of t encoded by encoding/json, those of the embedded
+// structs included
+func eachField(t reflect.Type, fn func(field reflect.StructField)) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && embedded.Kind() == reflect.Struct && tag == "" {
+			eachField(embedded, fn)
+			continue
+		}
+		if field.IsExported() {
+			fn(field)
+		}
+	}
+}
+
+// boundFrom returns where gin binds field from when not from the body: "path" for a uri tag
+// and "query" for a form tag, with the name of the parameter
+func boundFrom(field reflect.StructField) (string, string) {
+	if field.Tag.Get("json") != "" {
+		return "", ""
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("uri"), ","); name != "" && name != "-" {
+		return "path", name
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("form"), ","); name != "" && name != "-" {
+		return "query", name
+	}
+	return "", ""
+}
+
+// jsonName returns the key of field in JSON objects
+func jsonName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// invalidComponentChars matches what component names cannot hold
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// componentName names the component of t after its package and type, e.g. dto.UserResp
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" {
+		return sanitize(t.Name())
+	}
+	return sanitize(pkg + "." + t.Name())
+}
+
+func sanitize(name string) string {
+	return invalidComponentChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SwaggerUIVersion is the swagger-ui-dist release vendored in swagger-ui, keep it in sync with go:generate
+const SwaggerUIVersion = "5.17.14"
+
+//go:generate sh -c "curl -sSfL https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-5.17.14.tgz | tar -xz --strip-components=1 -C swagger-ui package/swagger-ui.css package/swagger-ui-bundle.js"
+
+// UIAssetsPath is where the vendored assets are served, under the path of the page
+const UIAssetsPath = "/assets"
+
+// uiAssets are the files of swagger-ui-dist the page loads
+var uiAssets = []string{"swagger-ui.css", "swagger-ui-bundle.js"}
+
+//go:embed ui.html
+var uiPage string
+
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+var uiTemplate = template.Must(template.New("ui").Parse(uiPage))
+
+// UIHandler serves the Swagger UI page of the document at specURL. The page loads the
+// swagger-ui-dist scripts and styles from assetsURL, such as the path AssetsHandler is served at.
+func UIHandler(title, specURL, assetsURL string) (http.Handler, error) {
+	var page bytes.Buffer
+	err := uiTemplate.Execute(&page, struct {
+		Title     string
+		SpecURL   string
+		AssetsURL string
+	}{title, specURL, strings.TrimSuffix(assetsURL, "/")})
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page.Bytes())
+	}), nil
+}
+
+// VendoredAssets reports whether the swagger-ui-dist files are embedded, see swagger-ui/README.md
+func VendoredAssets() bool {
+	for _, name := range uiAssets {
+		if _, err := fs.Stat(swaggerUI, "swagger-ui/"+name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// AssetsHandler serves the embedded swagger-ui-dist files by name, e.g. /swagger-ui.css
+func AssetsHandler() http.Handler {
+	assets, _ := fs.Sub(swaggerUI, "swagger-ui")
+	return assetsHandler(assets)
+}
+
+// assetsHandler serves the files of uiAssets found in assets
+func assetsHandler(assets fs.FS) http.Handler {
+	files := http.FileServerFS(assets)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(uiAssets, strings.TrimPrefix(r.URL.Path, "/")) {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
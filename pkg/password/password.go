@@ -0,0 +1,35 @@
+// Package password provides password hashing and verification
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultCost is the bcrypt work factor used for new hashes
+const DefaultCost = 12
+
+// MaxLength is the longest password in bytes bcrypt hashes, longer ones are rejected by Hash
+const MaxLength = 72
+
+// ErrMismatch is returned when a password does not match its hash
+var ErrMismatch = errors.New("password does not match")
+
+// Hash returns the bcrypt hash of a plain text password
+func Hash(plain string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Compare checks a plain text password against a bcrypt hash
+func Compare(hashed, plain string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(plain))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle keys are evicted from the memory limiter
+const sweepInterval = time.Minute
+
+// memoryEntry holds the state of a single key
+type memoryEntry struct {
+	// hits are the request times inside the sliding window
+	hits []time.Time
+	// tokens and refilled hold the token bucket state
+	tokens   float64
+	refilled time.Time
+	// expires is when the entry can be evicted
+	expires time.Time
+}
+
+// MemoryLimiter is a process local limiter, used on its own or as a fallback for Redis
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryLimiter creates an in-memory limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Allow applies the rule to key
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &memoryEntry{tokens: float64(rule.capacity()), refilled: now}
+		l.entries[key] = entry
+	}
+
+	if rule.Algorithm == AlgorithmTokenBucket {
+		return l.tokenBucket(entry, rule, now), nil
+	}
+	return l.slidingWindow(entry, rule, now), nil
+}
+
+func (l *MemoryLimiter) slidingWindow(entry *memoryEntry, rule Rule, now time.Time) *Result {
+	// Drop hits that fell out of the window
+	cutoff := now.Add(-rule.Window)
+	kept := entry.hits[:0]
+	for _, hit := range entry.hits {
+		if hit.After(cutoff) {
+			kept = append(kept, hit)
+		}
+	}
+	entry.hits = kept
+
+	result := &Result{Limit: rule.Limit}
+	if len(entry.hits) < rule.Limit {
+		entry.hits = append(entry.hits, now)
+		result.Allowed = true
+		result.Remaining = rule.Limit - len(entry.hits)
+	}
+
+	result.ResetAfter = entry.hits[0].Add(rule.Window).Sub(now)
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	entry.expires = now.Add(rule.Window)
+	return result
+}
+
+func (l *MemoryLimiter) tokenBucket(entry *memoryEntry, rule Rule, now time.Time) *Result {
+	capacity := float64(rule.capacity())
+	perToken := rule.Window / time.Duration(rule.Limit)
+
+	// Refill proportionally to the time since the last request
+	elapsed := now.Sub(entry.refilled)
+	entry.tokens = math.Min(capacity, entry.tokens+float64(elapsed)/float64(perToken))
+	entry.refilled = now
+
+	result := &Result{Limit: rule.capacity()}
+	if entry.tokens >= 1 {
+		entry.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - entry.tokens) * float64(perToken))
+	}
+
+	result.Remaining = int(entry.tokens)
+	result.ResetAfter = time.Duration((capacity - entry.tokens) * float64(perToken))
+	entry.expires = now.Add(result.ResetAfter)
+	return result
+}
+
+// sweep evicts entries whose state has fully expired
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, entry := range l.entries {
+		if now.After(entry.expires) {
+			delete(l.entries, key)
+		}
+	}
+}
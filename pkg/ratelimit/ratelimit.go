@@ -0,0 +1,110 @@
+// Package ratelimit provides request rate limiting backed by Redis or memory
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// Store names
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+)
+
+// Algorithm names
+const (
+	AlgorithmSlidingWindow = "sliding_window"
+	AlgorithmTokenBucket   = "token_bucket"
+)
+
+// Rule describes how many requests a key may make
+type Rule struct {
+	// Algorithm is either AlgorithmSlidingWindow or AlgorithmTokenBucket
+	Algorithm string
+	// Limit is the number of requests allowed per Window
+	Limit int
+	// Window is the period the limit applies to; token buckets refill Limit tokens per Window
+	Window time.Duration
+	// Burst is the token bucket capacity, defaults to Limit
+	Burst int
+}
+
+// capacity returns the maximum number of requests that may be made at once
+func (r Rule) capacity() int {
+	if r.Algorithm == AlgorithmTokenBucket && r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// Validate checks the rule can be enforced
+func (r Rule) Validate() error {
+	if r.Limit <= 0 {
+		return fmt.Errorf("rate limit must be positive, got %d", r.Limit)
+	}
+	if r.Window <= 0 {
+		return fmt.Errorf("rate limit window must be positive, got %s", r.Window)
+	}
+	switch r.Algorithm {
+	case AlgorithmSlidingWindow, AlgorithmTokenBucket:
+		return nil
+	default:
+		return fmt.Errorf("unsupported rate limit algorithm %q", r.Algorithm)
+	}
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	// Allowed reports whether the request may proceed
+	Allowed bool
+	// Limit is the maximum number of requests available at once
+	Limit int
+	// Remaining is the number of requests left
+	Remaining int
+	// ResetAfter is the time until the quota is fully restored
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request may be allowed, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a request identified by key is allowed under rule
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (*Result, error)
+}
+
+// FallbackLimiter uses the primary limiter and falls back to another when it fails
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	// degraded is set while the primary fails, so only changes are logged rather than every request
+	degraded atomic.Bool
+}
+
+// NewFallbackLimiter creates a limiter that degrades to fallback when primary returns an error
+func NewFallbackLimiter(primary, fallback Limiter) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Allow checks the primary limiter, using the fallback if the primary is unavailable
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	result, err := l.primary.Allow(ctx, key, rule)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			logger.SugarForComponent(ctx, logger.ComponentHTTP).Infof("Rate limiter available again, leaving fallback")
+		}
+		return result, nil
+	}
+
+	if l.degraded.CompareAndSwap(false, true) {
+		logger.SugarForComponent(ctx, logger.ComponentHTTP).Warnf("Rate limiter unavailable, using fallback until it recovers: %v", err)
+	}
+	return l.fallback.Allow(ctx, key, rule)
+}
@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ntdat104/go-clean-architecture/pkg/logger"
+)
+
+// fakeClock is a manually advanced clock shared by the limiters under test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newLimiters(t *testing.T) (map[string]Limiter, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: time.UnixMilli(1_700_000_000_000)}
+
+	memory := NewMemoryLimiter()
+	memory.now = clock.Now
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	redisLimiter := NewRedisLimiter(client)
+	redisLimiter.now = clock.Now
+
+	return map[string]Limiter{"memory": memory, "redis": redisLimiter}, clock
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limiters, clock := newLimiters(t)
+	rule := Rule{Algorithm: AlgorithmSlidingWindow, Limit: 3, Window: time.Minute}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "sliding:" + name
+
+			for i := 0; i < 3; i++ {
+				result, err := limiter.Allow(ctx, key, rule)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed)
+				assert.Equal(t, 2-i, result.Remaining)
+				clock.Advance(10 * time.Second)
+			}
+
+			result, err := limiter.Allow(ctx, key, rule)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+			assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+			// The first hit leaves the window after a minute
+			clock.Advance(30 * time.Second)
+			result, err = limiter.Allow(ctx, key, rule)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limiters, clock := newLimiters(t)
+	rule := Rule{Algorithm: AlgorithmTokenBucket, Limit: 6, Window: time.Minute, Burst: 2}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "bucket:" + name
+
+			for i := 0; i < 2; i++ {
+				result, err := limiter.Allow(ctx, key, rule)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed)
+				assert.Equal(t, 2, result.Limit)
+			}
+
+			result, err := limiter.Allow(ctx, key, rule)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, 10*time.Second, result.RetryAfter)
+
+			// One token is refilled every ten seconds
+			clock.Advance(10 * time.Second)
+			result, err = limiter.Allow(ctx, key, rule)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	assert.NoError(t, Rule{Algorithm: AlgorithmSlidingWindow, Limit: 1, Window: time.Second}.Validate())
+	assert.Error(t, Rule{Algorithm: AlgorithmSlidingWindow, Limit: 0, Window: time.Second}.Validate())
+	assert.Error(t, Rule{Algorithm: AlgorithmTokenBucket, Limit: 1}.Validate())
+	assert.Error(t, Rule{Algorithm: "fixed_window", Limit: 1, Window: time.Second}.Validate())
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestFallbackLimiter(t *testing.T) {
+	logger.SugaredLogger = zap.NewNop().Sugar()
+
+	limiter := NewFallbackLimiter(failingLimiter{}, NewMemoryLimiter())
+	rule := Rule{Algorithm: AlgorithmSlidingWindow, Limit: 1, Window: time.Minute}
+
+	result, err := limiter.Allow(context.Background(), "fallback", rule)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Allow(context.Background(), "fallback", rule)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+// switchableLimiter fails while down is set
+type switchableLimiter struct {
+	down bool
+}
+
+func (l *switchableLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if l.down {
+		return nil, errors.New("connection refused")
+	}
+	return &Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}, nil
+}
+
+func TestFallbackLimiterLogsTransitions(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = nil })
+
+	primary := &switchableLimiter{down: true}
+	limiter := NewFallbackLimiter(primary, NewMemoryLimiter())
+	rule := Rule{Algorithm: AlgorithmSlidingWindow, Limit: 100, Window: time.Minute}
+	allow := func(times int) {
+		for range times {
+			_, err := limiter.Allow(context.Background(), "fallback", rule)
+			require.NoError(t, err)
+		}
+	}
+
+	allow(3)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, zap.WarnLevel, logs.All()[0].Level)
+
+	primary.down = false
+	allow(3)
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, zap.InfoLevel, logs.All()[1].Level)
+
+	primary.down = true
+	allow(1)
+	assert.Equal(t, 3, logs.Len())
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/ntdat104/go-clean-architecture/pkg/uuid"
+)
+
+// keyPrefix namespaces rate limit keys in Redis
+const keyPrefix = "ratelimit:"
+
+// slidingWindowScript keeps one sorted set member per request inside the window.
+// Returns {allowed, remaining, reset_ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local reset = window
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+// tokenBucketScript stores the token count and last refill time in a hash.
+// Returns {allowed, remaining, reset_ms, retry_ms}.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local per_token = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + (now - ts) / per_token)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * per_token)
+end
+
+local reset = math.ceil((capacity - tokens) * per_token)
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// RedisLimiter is a limiter shared by every instance through Redis
+type RedisLimiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+// NewRedisLimiter creates a Redis backed limiter
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Allow applies the rule to key
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := l.now().UnixMilli()
+	redisKey := keyPrefix + rule.Algorithm + ":" + key
+
+	if rule.Algorithm == AlgorithmTokenBucket {
+		perToken := float64(rule.Window.Milliseconds()) / float64(rule.Limit)
+		values, err := tokenBucketScript.Run(ctx, l.client, []string{redisKey},
+			now, strconv.FormatFloat(perToken, 'f', -1, 64), rule.capacity()).Int64Slice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run token bucket script: %w", err)
+		}
+		return &Result{
+			Allowed:    values[0] == 1,
+			Limit:      rule.capacity(),
+			Remaining:  int(values[1]),
+			ResetAfter: time.Duration(values[2]) * time.Millisecond,
+			RetryAfter: time.Duration(values[3]) * time.Millisecond,
+		}, nil
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.client, []string{redisKey},
+		now, rule.Window.Milliseconds(), rule.Limit, uuid.NewShortUUID()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run sliding window script: %w", err)
+	}
+
+	result := &Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  int(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Millisecond,
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	return result, nil
+}
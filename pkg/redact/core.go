@@ -0,0 +1,53 @@
+package redact
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// core is a zapcore.Core masking fields whose key is sensitive
+type core struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+// NewCore wraps a core so fields such as zap.String("password", ...) are masked before being written
+func NewCore(c zapcore.Core, redactor *Redactor) zapcore.Core {
+	return &core{Core: c, redactor: redactor}
+}
+
+// With masks sensitive fields added to a child logger
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+// Check registers this core so Write sees the entry
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write masks sensitive fields before writing the entry
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields returns fields with sensitive ones replaced by the mask, copying only when needed
+func (c *core) redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		if !c.redactor.Field([]string{field.Key}) {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]zapcore.Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: c.redactor.mask}
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
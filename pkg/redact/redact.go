@@ -0,0 +1,269 @@
+// Package redact removes secrets from headers, bodies and log fields before they are logged
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+// DefaultMask replaces redacted values
+const DefaultMask = "[REDACTED]"
+
+// DefaultMaxBodySize is the number of body bytes logged when no limit is configured
+const DefaultMaxBodySize = 1024
+
+// Defaults used for settings missing from the configuration
+var (
+	DefaultDenyHeaders = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+		"X-Api-Secret",
+		"Signature",
+	}
+	DefaultFields = []string{
+		"password",
+		"password_hash",
+		"secret",
+		"token",
+		"access_token",
+		"refresh_token",
+		"api_key",
+		"api_secret",
+		"authorization",
+	}
+	DefaultContentTypes = []string{
+		"application/json",
+		"application/x-www-form-urlencoded",
+		"text/plain",
+	}
+)
+
+// Redactor masks sensitive values according to the redaction configuration
+type Redactor struct {
+	mask         string
+	denyHeaders  map[string]bool
+	allowHeaders map[string]bool
+	fields       []fieldPath
+	maxBodySize  int
+	contentTypes map[string]bool
+	sampleRate   float64
+}
+
+// New creates a redactor from the configuration; nil or empty settings use the defaults
+func New(conf *config.RedactionConfig) *Redactor {
+	if conf == nil {
+		conf = &config.RedactionConfig{}
+	}
+
+	r := &Redactor{
+		mask:         conf.Mask,
+		denyHeaders:  toSet(withDefault(conf.DenyHeaders, DefaultDenyHeaders), http.CanonicalHeaderKey),
+		allowHeaders: toSet(conf.AllowHeaders, http.CanonicalHeaderKey),
+		maxBodySize:  conf.MaxBodySize,
+		contentTypes: toSet(withDefault(conf.ContentTypes, DefaultContentTypes), strings.ToLower),
+		sampleRate:   conf.BodySampleRate,
+	}
+	if r.mask == "" {
+		r.mask = DefaultMask
+	}
+	if r.maxBodySize <= 0 {
+		r.maxBodySize = DefaultMaxBodySize
+	}
+	if r.sampleRate <= 0 || r.sampleRate > 1 {
+		r.sampleRate = 1
+	}
+	for _, field := range withDefault(conf.Fields, DefaultFields) {
+		r.fields = append(r.fields, parseFieldPath(field))
+	}
+
+	return r
+}
+
+// Mask returns the replacement used for redacted values
+func (r *Redactor) Mask() string {
+	return r.mask
+}
+
+// SampleBody reports whether bodies of the current request should be logged
+func (r *Redactor) SampleBody() bool {
+	return r.sampleRate >= 1 || rand.Float64() < r.sampleRate
+}
+
+// Headers returns the allow listed headers, masking denied ones.
+// Nothing is returned when no allow list is configured.
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	if len(r.allowHeaders) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(r.allowHeaders))
+	for name, values := range header {
+		name = http.CanonicalHeaderKey(name)
+		if !r.allowHeaders[name] {
+			continue
+		}
+		result[name] = r.Header(name, strings.Join(values, ", "))
+	}
+	return result
+}
+
+// Header returns value, masked if the header is denied
+func (r *Redactor) Header(name, value string) string {
+	if value != "" && r.denyHeaders[http.CanonicalHeaderKey(name)] {
+		return r.mask
+	}
+	return value
+}
+
+// MaxBodySize returns the number of body bytes logged
+func (r *Redactor) MaxBodySize() int {
+	return r.maxBodySize
+}
+
+// LogsContentType reports whether bodies of the content type may be logged
+func (r *Redactor) LogsContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && r.contentTypes[strings.ToLower(mediaType)]
+}
+
+// Body returns the loggable form of a body with sensitive fields masked, capped at the maximum body size.
+// It returns false when the content type must not be logged.
+func (r *Redactor) Body(contentType string, body []byte) (string, bool) {
+	if len(body) == 0 || !r.LogsContentType(contentType) {
+		return "", false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var redacted []byte
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		redacted = r.jsonBody(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		redacted = r.formBody(body)
+	default:
+		redacted = body
+	}
+
+	if len(redacted) > r.maxBodySize {
+		return fmt.Sprintf("%s...(truncated, %d bytes)", redacted[:r.maxBodySize], len(redacted)), true
+	}
+	return string(redacted), true
+}
+
+// jsonBody masks sensitive fields of a JSON document; unparseable documents are not logged
+func (r *Redactor) jsonBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return []byte(fmt.Sprintf("[unparseable body omitted, %d bytes]", len(body)))
+	}
+
+	out, err := json.Marshal(r.redactValue(nil, doc))
+	if err != nil {
+		return []byte(fmt.Sprintf("[unparseable body omitted, %d bytes]", len(body)))
+	}
+	return out
+}
+
+// formBody masks sensitive fields of a URL encoded form
+func (r *Redactor) formBody(body []byte) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return []byte(fmt.Sprintf("[unparseable body omitted, %d bytes]", len(body)))
+	}
+	for key := range values {
+		if r.Field([]string{key}) {
+			values[key] = []string{r.mask}
+		}
+	}
+	return []byte(values.Encode())
+}
+
+// redactValue walks a decoded JSON value, masking every field whose path matches
+func (r *Redactor) redactValue(path []string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if r.Field(childPath) {
+				v[key] = r.mask
+				continue
+			}
+			v[key] = r.redactValue(childPath, child)
+		}
+		return v
+	case []any:
+		// Array elements share the path of the array
+		for i, child := range v {
+			v[i] = r.redactValue(path, child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Field reports whether the value at the key path is sensitive
+func (r *Redactor) Field(path []string) bool {
+	for _, field := range r.fields {
+		if field.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPath is a dotted field pattern such as "password", "user.password" or "*.token".
+// Patterns match the end of a key path, so "password" matches that key at any depth
+// and "*" matches exactly one key.
+type fieldPath []string
+
+// parseFieldPath splits a dotted pattern into lower case segments
+func parseFieldPath(pattern string) fieldPath {
+	return strings.Split(strings.ToLower(strings.TrimSpace(pattern)), ".")
+}
+
+// matches reports whether the pattern matches the end of path, ignoring case
+func (p fieldPath) matches(path []string) bool {
+	if len(path) < len(p) {
+		return false
+	}
+	offset := len(path) - len(p)
+	for i, segment := range p {
+		if segment != "*" && segment != strings.ToLower(path[offset+i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// toSet builds a lookup set of normalized values
+func toSet(values []string, normalize func(string) string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[normalize(strings.TrimSpace(value))] = true
+	}
+	return set
+}
+
+// withDefault returns values, or fallback when values is empty
+func withDefault(values, fallback []string) []string {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
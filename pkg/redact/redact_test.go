@@ -0,0 +1,110 @@
+package redact
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHeaders(t *testing.T) {
+	r := New(&config.RedactionConfig{AllowHeaders: []string{"user-agent", "authorization"}})
+
+	assert.Equal(t, DefaultMask, r.Header("authorization", "Bearer abc"))
+	assert.Equal(t, "", r.Header("Authorization", ""))
+	assert.Equal(t, "application/json", r.Header("Content-Type", "application/json"))
+
+	header := http.Header{}
+	header.Set("User-Agent", "curl")
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Content-Type", "application/json")
+	assert.Equal(t, map[string]string{
+		"User-Agent":    "curl",
+		"Authorization": DefaultMask,
+	}, r.Headers(header))
+
+	assert.Nil(t, New(nil).Headers(header))
+}
+
+func TestJSONBody(t *testing.T) {
+	r := New(&config.RedactionConfig{
+		Fields: []string{"password", "*.token", "card.number"},
+	})
+
+	body, ok := r.Body("application/json; charset=utf-8", []byte(`{
+		"name": "alice",
+		"password": "s3cret",
+		"token": "top-level",
+		"session": {"token": "nested", "id": 7},
+		"items": [{"card": {"number": "4111", "brand": "visa"}}],
+		"profile": {"Password": "again"}
+	}`))
+	require.True(t, ok)
+	assert.JSONEq(t, `{
+		"name": "alice",
+		"password": "[REDACTED]",
+		"token": "top-level",
+		"session": {"token": "[REDACTED]", "id": 7},
+		"items": [{"card": {"number": "[REDACTED]", "brand": "visa"}}],
+		"profile": {"Password": "[REDACTED]"}
+	}`, body)
+
+	// Malformed JSON is never logged verbatim
+	body, ok = r.Body("application/json", []byte(`{"password": "s3cret"`))
+	require.True(t, ok)
+	assert.NotContains(t, body, "s3cret")
+}
+
+func TestBodyFilters(t *testing.T) {
+	r := New(&config.RedactionConfig{MaxBodySize: 10})
+
+	_, ok := r.Body("application/octet-stream", []byte("binary"))
+	assert.False(t, ok)
+	_, ok = r.Body("", []byte("no content type"))
+	assert.False(t, ok)
+	_, ok = r.Body("text/plain", nil)
+	assert.False(t, ok)
+	assert.True(t, r.LogsContentType("Application/JSON; charset=utf-8"))
+	assert.False(t, r.LogsContentType("multipart/form-data; boundary=x"))
+
+	body, ok := r.Body("text/plain", []byte(strings.Repeat("a", 20)))
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(body, strings.Repeat("a", 10)+"...(truncated, 20 bytes)"))
+
+	body, ok = New(nil).Body("application/x-www-form-urlencoded", []byte("user=bob&password=s3cret"))
+	require.True(t, ok)
+	assert.Equal(t, "password=%5BREDACTED%5D&user=bob", body)
+}
+
+func TestSampleBody(t *testing.T) {
+	assert.True(t, New(nil).SampleBody())
+
+	r := New(&config.RedactionConfig{BodySampleRate: 0.5})
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if r.SampleBody() {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(NewCore(observed, New(nil))).With(zap.String("api_key", "k"))
+
+	log.Info("login", zap.String("user", "bob"), zap.String("password", "s3cret"))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "bob", fields["user"])
+	assert.Equal(t, DefaultMask, fields["password"])
+	assert.Equal(t, DefaultMask, fields["api_key"])
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook creates a client span for every Redis command and pipeline.
+// Command arguments are not recorded as they may hold cached personal data.
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+// NewRedisHook creates a hook to add with (*redis.Client).AddHook
+func NewRedisHook() RedisHook {
+	return RedisHook{}
+}
+
+// BeforeProcess implements redis.Hook
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = Start(ctx, "redis "+cmd.Name(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameRedis,
+			semconv.DBOperationName(cmd.FullName()),
+		),
+	)
+	return ctx, nil
+}
+
+// AfterProcess implements redis.Hook
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	End(trace.SpanFromContext(ctx), redisError(cmd.Err()))
+	return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.Name()
+	}
+	ctx, _ = Start(ctx, "redis pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameRedis,
+			semconv.DBOperationName("pipeline"),
+			attribute.String("db.redis.commands", strings.Join(names, " ")),
+			semconv.DBOperationBatchSize(len(cmds)),
+		),
+	)
+	return ctx, nil
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = redisError(cmd.Err()); err != nil {
+			break
+		}
+	}
+	End(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+// redisError drops redis.Nil, which reports a missing key rather than a failure
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
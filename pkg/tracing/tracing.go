@@ -0,0 +1,185 @@
+// Package tracing configures OpenTelemetry tracing and provides helpers to create spans
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+// Exporters selectable in tracing.exporter
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// InstrumentationName identifies the spans created by the application
+const InstrumentationName = "github.com/ntdat104/go-clean-architecture"
+
+// ShutdownFunc flushes pending spans and releases the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Init installs the W3C trace context and baggage propagators and, when tracing is enabled,
+// a tracer provider exporting to the configured exporter.
+// With tracing disabled, incoming trace IDs are still propagated so logs can be correlated.
+func Init(ctx context.Context) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	tracingConfig := config.GlobalConfig.Tracing
+	if tracingConfig == nil || !tracingConfig.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider, err := NewProvider(ctx, tracingConfig)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider exporting spans as configured by tracingConfig
+func NewProvider(ctx context.Context, tracingConfig *config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(ctx, tracingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(tracingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := tracingConfig.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	), nil
+}
+
+// newExporter creates the span exporter selected in the configuration
+func newExporter(ctx context.Context, tracingConfig *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch tracingConfig.Exporter {
+	case ExporterOTLP, "":
+		opts := []otlptracehttp.Option{}
+		if tracingConfig.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(tracingConfig.Endpoint))
+		}
+		if tracingConfig.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		if tracingConfig.FilePath == "" {
+			return nil, errors.New("tracing.file_path is required by the file exporter")
+		}
+		if err := os.MkdirAll(filepath.Dir(tracingConfig.FilePath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create trace directory: %w", err)
+		}
+		file, err := os.OpenFile(tracingConfig.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &closingExporter{SpanExporter: exporter, closer: file}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", tracingConfig.Exporter)
+	}
+}
+
+// newResource describes the service emitting the spans
+func newResource(tracingConfig *config.TracingConfig) (*resource.Resource, error) {
+	serviceName := tracingConfig.ServiceName
+	var serviceVersion string
+	if app := config.GlobalConfig.App; app != nil {
+		if serviceName == "" {
+			serviceName = app.Name
+		}
+		serviceVersion = app.Version
+	}
+
+	return resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+		semconv.DeploymentEnvironmentName(string(config.GlobalConfig.Env)),
+	))
+}
+
+// closingExporter closes the file it writes to once the exporter has shut down
+type closingExporter struct {
+	sdktrace.SpanExporter
+	closer io.Closer
+}
+
+// Shutdown implements sdktrace.SpanExporter
+func (e *closingExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.closer.Close())
+}
+
+// Tracer returns the application tracer from the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start starts a span named name as a child of the span in ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End records err on span, marking it failed, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the trace ID of the span in ctx, or an empty string when there is none
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// SpanID returns the ID of the span in ctx, or an empty string when there is none
+func SpanID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return ""
+	}
+	return spanContext.SpanID().String()
+}
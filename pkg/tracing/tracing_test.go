@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ntdat104/go-clean-architecture/config"
+)
+
+// useRecorder installs a tracer provider recording spans in memory for the duration of the test
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInitFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "spans.json")
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{
+		App: &config.AppConfig{Name: "test-service", Version: "1.0.0"},
+		Tracing: &config.TracingConfig{
+			Enabled:  true,
+			Exporter: ExporterFile,
+			FilePath: path,
+		},
+	}
+	previousProvider := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		config.GlobalConfig = previous
+		otel.SetTracerProvider(previousProvider)
+	})
+
+	shutdown, err := Init(context.Background())
+	require.NoError(t, err)
+
+	ctx, span := Start(context.Background(), "operation")
+	traceID := TraceID(ctx)
+	assert.Len(t, traceID, 32)
+	assert.Len(t, SpanID(ctx), 16)
+	span.End()
+
+	// Shutdown flushes the batched spans and closes the file
+	require.NoError(t, shutdown(context.Background()))
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), traceID)
+	assert.Contains(t, string(written), "test-service")
+}
+
+func TestInitDisabled(t *testing.T) {
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{Tracing: &config.TracingConfig{Exporter: "unknown"}}
+	t.Cleanup(func() { config.GlobalConfig = previous })
+
+	shutdown, err := Init(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = NewProvider(context.Background(), config.GlobalConfig.Tracing)
+	assert.Error(t, err)
+	assert.Empty(t, TraceID(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	recorder := useRecorder(t)
+
+	_, span := Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = Start(context.Background(), "failed")
+	End(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := useRecorder(t)
+
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+	rdb.AddHook(NewRedisHook())
+
+	ctx, parent := Start(context.Background(), "parent")
+	require.NoError(t, rdb.Set(ctx, "key", "value", 0).Err())
+	// A missing key is not a failure
+	assert.ErrorIs(t, rdb.Get(ctx, "missing").Err(), redis.Nil)
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "counter")
+		pipe.Expire(ctx, "counter", 0)
+		return nil
+	})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, "redis set", spans[0].Name())
+	assert.Equal(t, "redis get", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, "redis pipeline", spans[2].Name())
+	for _, span := range spans[:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
@@ -1,9 +1,27 @@
 -- tb_users
+-- name is the username the username validator checks, an identity as unique as email, not a display name
 CREATE TABLE IF NOT EXISTS users (
     id INT AUTO_INCREMENT PRIMARY KEY,
     uuid CHAR(36) NOT NULL UNIQUE,
-    name VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL UNIQUE,
     email VARCHAR(255) NOT NULL UNIQUE,
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    password_hash VARCHAR(255) NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-);
\ No newline at end of file
+);
+
+-- Upgrade users tables created before accounts had credentials. RunMigration skips these statements
+-- once applied. Adding the unique index fails while accounts share a name: rename them first.
+ALTER TABLE users ADD COLUMN phone VARCHAR(32) NOT NULL DEFAULT '' AFTER email;
+ALTER TABLE users ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '' AFTER phone;
+ALTER TABLE users ADD UNIQUE KEY name (name);
+
+-- tb_role_permissions
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    role VARCHAR(64) NOT NULL,
+    permission VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_role_permission (role, permission)
+);